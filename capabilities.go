@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// kiloGroups are Kilo's tool groups in the order modes list them
+var kiloGroups = []string{"read", "edit", "browser", "command", "mcp"}
+
+// RegionTools marks capability evidence from a Claude agent's declared tools rather than its prose
+const RegionTools RegionKind = "tools"
+
+// capabilityWindow is how many words may separate a verb from its object ("run the full test suite")
+const capabilityWindow = 4
+
+// blockerWindow is how far before a verb a negation or hedge cancels it ("never edit", "suggest fixes")
+const blockerWindow = 2
+
+// capability is a tool group and the prompt phrasing that shows an agent needs it
+type capability struct {
+	group   string
+	verbs   []string // Count when one of objects follows within capabilityWindow words
+	objects []string
+	terms   []string // Count on their own
+}
+
+// capabilities are checked in kiloGroups order; read is granted to every mode
+var capabilities = []capability{
+	{
+		group: "edit",
+		verbs: []string{"edit", "write", "modify", "change", "update", "create", "fix", "add", "patch", "rewrite", "generate",
+			"scaffold", "apply", "build", "draft", "maintain", "correct"},
+		objects: []string{"file", "code", "test", "function", "component", "module", "class", "implementation", "config",
+			"configuration", "migration", "schema", "doc", "documentation", "readme", "script", "type", "endpoint", "feature",
+			"bug", "app", "pipeline", "changelog", "guide", "tutorial", "copy", "prose", "grammar", "spelling", "typo"},
+		terms: []string{"refactor", "implement"},
+	},
+	{
+		group:   "browser",
+		verbs:   []string{"open", "visit", "browse", "fetch", "navigate", "load", "check", "search", "screenshot"},
+		objects: []string{"url", "link", "page", "website", "site", "web", "browser"},
+		terms:   []string{"browser", "screenshot", "playwright", "puppeteer", "selenium", "web search"},
+	},
+	{
+		group:   "command",
+		verbs:   []string{"run", "execute", "invoke", "launch", "start"},
+		objects: []string{"test", "command", "script", "suite", "build", "linter", "lint", "benchmark", "migration", "server", "container", "pipeline", "check"},
+		terms:   []string{"terminal", "shell", "bash", "command line", "npm", "yarn", "pnpm", "pip", "pytest", "jest", "go test", "cargo", "git", "kubectl", "compile", "deploy"},
+	},
+	{
+		group: "mcp",
+		terms: []string{"mcp", "model context protocol"},
+	},
+}
+
+// capabilityBlockers cancel a verb or term shortly after them; "t" is what's left of "don't"
+var capabilityBlockers = map[string]bool{
+	"not": true, "never": true, "t": true, "no": true, "without": true, "avoid": true,
+	stem("suggest"): true, stem("recommend"): true, stem("propose"): true,
+}
+
+// inferCapabilities collects the evidence for each group from the agent's prose
+func inferCapabilities(text *AnalysisText) map[string]*CandidateScore {
+	evidence := make(map[string]*CandidateScore)
+	for _, capability := range capabilities {
+		candidate := &CandidateScore{Value: capability.group}
+		for _, region := range text.Regions {
+			found := make(map[string]int)
+			for _, verb := range capability.verbs {
+				v := stem(verb)
+				for i, token := range region.Tokens {
+					if token != v || blocked(region.Tokens, i) {
+						continue
+					}
+					for j := i + 1; j <= i+capabilityWindow && j < len(region.Tokens); j++ {
+						if object := objectAt(capability.objects, region.Tokens[j]); object != "" {
+							found[verb+" "+object]++
+							break
+						}
+					}
+				}
+			}
+			for _, term := range capability.terms {
+				for _, i := range compileKeyword(term).indexesIn(region.Tokens) {
+					if !blocked(region.Tokens, i) {
+						found[term]++
+					}
+				}
+			}
+			for _, phrase := range sortedKeys(found) {
+				candidate.Matches = append(candidate.Matches, KeywordMatch{Keyword: phrase, Region: region.Kind, Count: found[phrase], Weight: region.Weight})
+				candidate.Score += region.Weight * found[phrase]
+			}
+		}
+		evidence[capability.group] = candidate
+	}
+	return evidence
+}
+
+// objectAt returns the object a token is, if any
+func objectAt(objects []string, token string) string {
+	for _, object := range objects {
+		if stem(object) == token {
+			return object
+		}
+	}
+	return ""
+}
+
+// blocked reports whether a negation or hedge shortly precedes position i
+func blocked(tokens []string, i int) bool {
+	for j := max(0, i-blockerWindow); j < i; j++ {
+		if capabilityBlockers[tokens[j]] {
+			return true
+		}
+	}
+	return false
+}
+
+// toolCapabilities collects the evidence for each group from a declared Claude tools list
+func toolCapabilities(tools []string) map[string]*CandidateScore {
+	evidence := make(map[string]*CandidateScore)
+	for _, tool := range tools {
+		tool = strings.TrimSpace(tool)
+		group := claudeToolGroups[tool]
+		if strings.HasPrefix(tool, "mcp__") {
+			group = "mcp"
+		}
+		if group == "" {
+			continue
+		}
+		if evidence[group] == nil {
+			evidence[group] = &CandidateScore{Value: group}
+		}
+		evidence[group].Score++
+		evidence[group].Matches = append(evidence[group].Matches, KeywordMatch{Keyword: tool, Region: RegionTools, Count: 1, Weight: 1})
+	}
+	return evidence
+}
+
+// determineGroups grants read plus each group the agent shows it needs. A declared
+// Claude tools list is authoritative; otherwise the prompt's phrasing is the evidence.
+func (c *Converter) determineGroups(text *AnalysisText, tools []string) ([]string, *DecisionTrace) {
+	trace := &DecisionTrace{Field: "groups"}
+	evidence := toolCapabilities(tools)
+	source := "declared tools"
+	if len(tools) == 0 {
+		evidence = inferCapabilities(text)
+		source = "prompt"
+	}
+
+	groups := []string{"read"}
+	var granted []string
+	for _, group := range kiloGroups[1:] {
+		if candidate := evidence[group]; candidate != nil {
+			trace.Candidates = append(trace.Candidates, *candidate)
+			if candidate.Score > 0 {
+				groups = append(groups, group)
+				granted = append(granted, fmt.Sprintf("%s (%s)", group, strings.Join(matchedKeywords(candidate.Matches), ", ")))
+			}
+		}
+	}
+
+	trace.Value = strings.Join(groups, ", ")
+	if len(granted) == 0 {
+		trace.Rule = fmt.Sprintf("least privilege: read only, no capability evidence in the %s", source)
+	} else {
+		trace.Rule = fmt.Sprintf("least privilege: read plus evidence in the %s for %s", source, strings.Join(granted, "; "))
+	}
+	return groups, trace
+}
+
+// ParseGroupList parses a comma-separated list of Kilo groups, such as the -max-groups policy
+func ParseGroupList(list string) ([]string, error) {
+	var groups []string
+	for _, group := range strings.Split(list, ",") {
+		group = strings.TrimSpace(group)
+		if group == "" {
+			continue
+		}
+		if !isKiloGroup(group) {
+			return nil, fmt.Errorf("unknown group %q (use %s)", group, strings.Join(kiloGroups, ", "))
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// isKiloGroup reports whether name is one of Kilo's tool groups
+func isKiloGroup(name string) bool {
+	for _, group := range kiloGroups {
+		if group == name {
+			return true
+		}
+	}
+	return false
+}
+
+// capGroups removes the groups the -max-groups policy does not allow
+func (c *Converter) capGroups(groups []string) (kept, removed []string) {
+	if c.maxGroups == nil {
+		return groups, nil
+	}
+	return intersectGroups(groups, c.maxGroups)
+}
+
+// intersectGroups keeps the groups that are also in allowed, in their original order,
+// and returns the others sorted
+func intersectGroups(groups, allowed []string) (kept, removed []string) {
+	allowedSet := make(map[string]bool)
+	for _, group := range allowed {
+		allowedSet[group] = true
+	}
+	for _, group := range groups {
+		if allowedSet[group] {
+			kept = append(kept, group)
+		} else {
+			removed = append(removed, group)
+		}
+	}
+	sort.Strings(removed)
+	return kept, removed
+}
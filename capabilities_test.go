@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetermineGroups_Evidence(t *testing.T) {
+	c := NewConverter()
+	cases := []struct{ name, body, want string }{
+		{"implementer", "Implement the feature, then run the full test suite.", "read, edit, command"},
+		{"qa-browser", "Open each page in the browser and take a screenshot.", "read, browser"},
+		{"mcp-helper", "Use the MCP server to query tickets.", "read, mcp"},
+		{"auditor", "Never edit files. Do not run commands; suggest fixes to the code instead.", "read"},
+		{"idle", "You answer questions about the codebase.", "read"},
+	}
+	for _, tc := range cases {
+		groups, trace := c.determineGroups(newAnalysisText(tc.name, "", tc.body), nil)
+		if got := strings.Join(groups, ", "); got != tc.want {
+			t.Errorf("%s: got %s, want %s (%s)", tc.name, got, tc.want, trace.Rule)
+		}
+	}
+}
+
+func TestDetermineGroups_DeclaredTools(t *testing.T) {
+	c := NewConverter()
+	text := newAnalysisText("reviewer", "", "Run the tests and fix any failing code.")
+	groups, trace := c.determineGroups(text, []string{"Read", "Grep", "WebFetch", "mcp__github__get_issue", "TodoWrite"})
+	if got := strings.Join(groups, ", "); got != "read, browser, mcp" {
+		t.Errorf("Expected only the declared tools' groups, got %s", got)
+	}
+	if !strings.Contains(trace.Rule, "declared tools") || !strings.Contains(trace.Rule, "browser (WebFetch)") {
+		t.Errorf("Expected the tools as evidence, got %q", trace.Rule)
+	}
+}
+
+func TestBuildMode_MaxGroups(t *testing.T) {
+	c := NewConverter()
+	var err error
+	if c.maxGroups, err = ParseGroupList("read, edit"); err != nil {
+		t.Fatal(err)
+	}
+
+	mode := c.buildMode(&SourceAgent{Name: "runner", Description: "Runs tests", Body: "Run the test suite and fix the code.", Format: "claude"})
+	if got := strings.Join(mode.Groups, ", "); got != "read, edit" {
+		t.Errorf("Expected command to be capped, got %s", got)
+	}
+	if len(mode.Losses) == 0 || mode.Losses[len(mode.Losses)-1].Cause != "command removed by -max-groups" {
+		t.Errorf("Expected the capped group as a loss, got %+v", mode.Losses)
+	}
+
+	// Groups given by the source are capped too, and the cap is traced
+	mode = c.buildMode(&SourceAgent{Name: "roo", Description: "Roo mode", Groups: []string{"read", "browser", "mcp"}, Format: "roo"})
+	if got := strings.Join(mode.Groups, ", "); got != "read" {
+		t.Errorf("Expected source groups to be capped, got %s", got)
+	}
+	if mode.Traces[0].Field != "groups" || !strings.Contains(mode.Traces[0].Rule, "-max-groups removed browser, mcp") {
+		t.Errorf("Expected a groups trace for the cap, got %+v", mode.Traces[0])
+	}
+
+	if _, err := ParseGroupList("read,admin"); err == nil {
+		t.Error("Expected an unknown group to be rejected")
+	}
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// catalogDir is where -catalog writes, inside the output directory
+const catalogDir = "catalog"
+
+// catalogTopLevel groups modes converted from files directly in the input directory
+const catalogTopLevel = "Top level"
+
+// CatalogGroup is one section of the catalog: the modes of a plugin or source directory
+type CatalogGroup struct {
+	Name  string
+	Modes []KiloMode
+}
+
+// ParseCatalogFormats parses the -catalog flag: html, markdown or both, comma-separated
+func ParseCatalogFormats(list string) ([]string, error) {
+	var formats []string
+	for _, format := range strings.Split(list, ",") {
+		switch format = strings.TrimSpace(format); format {
+		case "":
+		case "html", "markdown":
+			formats = append(formats, format)
+		case "md":
+			formats = append(formats, "markdown")
+		default:
+			return nil, fmt.Errorf("unknown catalog format %q (use html, markdown or both)", format)
+		}
+	}
+	return formats, nil
+}
+
+// groupCatalog sorts modes into groups by plugin, or by the directory of their source file
+func groupCatalog(modes []KiloMode) []CatalogGroup {
+	byGroup := make(map[string][]KiloMode)
+	for _, mode := range modes {
+		group := mode.Plugin
+		if group == "" {
+			group = filepath.ToSlash(filepath.Dir(mode.SourceFile))
+		}
+		if group == "." || group == "" {
+			group = catalogTopLevel
+		}
+		byGroup[group] = append(byGroup[group], mode)
+	}
+
+	var groups []CatalogGroup
+	for _, name := range sortedKeys(byGroup) {
+		modes := byGroup[name]
+		sort.SliceStable(modes, func(i, j int) bool { return strings.ToLower(modes[i].Name) < strings.ToLower(modes[j].Name) })
+		groups = append(groups, CatalogGroup{Name: name, Modes: modes})
+	}
+	return groups
+}
+
+// writeCatalog renders an index and a page per mode for every requested format and
+// returns the catalog directory
+func (c *Converter) writeCatalog(modes []KiloMode, outputDir string, formats []string) (string, error) {
+	dir := filepath.Join(outputDir, catalogDir)
+	if err := os.MkdirAll(filepath.Join(dir, "modes"), 0755); err != nil {
+		return "", fmt.Errorf("failed to create catalog directory: %w", err)
+	}
+
+	groups := groupCatalog(modes)
+	for _, format := range formats {
+		var err error
+		if format == "html" {
+			err = writeHTMLCatalog(dir, groups)
+		} else {
+			err = writeMarkdownCatalog(dir, groups)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// catalogFuncs are shared by the HTML catalog templates
+var catalogFuncs = template.FuncMap{
+	"emoji":  emojiFor,
+	"join":   strings.Join,
+	"search": catalogSearchText,
+	"total": func(groups []CatalogGroup) int {
+		total := 0
+		for _, group := range groups {
+			total += len(group.Modes)
+		}
+		return total
+	},
+}
+
+// catalogSearchText is what the index's search box matches a mode against
+func catalogSearchText(mode KiloMode) string {
+	return strings.ToLower(strings.Join([]string{mode.Slug, mode.Name, mode.Description, mode.WhenToUse,
+		strings.Join(mode.Groups, " "), mode.SourceFile, mode.OriginalModel, mode.Plugin}, " "))
+}
+
+// catalogStyle is inlined into every page so the catalog works offline
+const catalogStyle = `<style>
+body { font-family: system-ui, sans-serif; margin: 2rem auto; max-width: 72rem; padding: 0 1rem; color: #1f2328; }
+a { color: #0969da; text-decoration: none; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2rem; }
+th, td { border-bottom: 1px solid #d0d7de; padding: .5rem; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+code, pre { font-family: ui-monospace, monospace; font-size: .9em; }
+pre { background: #f6f8fa; padding: 1rem; white-space: pre-wrap; overflow-wrap: anywhere; }
+.group { background: #ddf4ff; border-radius: 1em; padding: 0 .5em; margin-right: .25em; font-size: .85em; }
+#search { font-size: 1rem; padding: .5rem; width: 100%; box-sizing: border-box; margin-bottom: 1rem; }
+</style>`
+
+var catalogIndexTemplate = template.Must(template.New("index").Funcs(catalogFuncs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Kilo Code Modes</title>
+` + catalogStyle + `
+</head>
+<body>
+<h1>Kilo Code Modes</h1>
+<p>{{total .}} modes converted from Claude agents.</p>
+<input id="search" type="search" placeholder="Search modes by name, description, groups, source or model" autofocus>
+<p id="no-results" hidden>No modes match.</p>
+{{range .}}<section class="catalog-group">
+<h2>{{.Name}}</h2>
+<table>
+<thead><tr><th>Mode</th><th>Icon</th><th>Description</th><th>When to use</th><th>Groups</th><th>Source</th><th>Model</th></tr></thead>
+<tbody>
+{{range .Modes}}<tr data-search="{{search .}}">
+<td><a href="modes/{{.Slug}}.html">{{.Name}}</a><br><code>{{.Slug}}</code></td>
+<td title="{{.IconName}}">{{emoji .IconName}} <code>{{.IconName}}</code></td>
+<td>{{.Description}}</td>
+<td>{{.WhenToUse}}</td>
+<td>{{range .Groups}}<span class="group">{{.}}</span>{{end}}</td>
+<td><code>{{.SourceFile}}</code></td>
+<td>{{.OriginalModel}}</td>
+</tr>
+{{end}}</tbody>
+</table>
+</section>
+{{end}}<script>
+const search = document.getElementById("search");
+search.addEventListener("input", () => {
+  const terms = search.value.toLowerCase().split(/\s+/).filter(Boolean);
+  let shown = 0;
+  for (const section of document.querySelectorAll(".catalog-group")) {
+    let visible = 0;
+    for (const row of section.querySelectorAll("tbody tr")) {
+      const match = terms.every(term => row.dataset.search.includes(term));
+      row.hidden = !match;
+      if (match) visible++;
+    }
+    section.hidden = visible === 0;
+    shown += visible;
+  }
+  document.getElementById("no-results").hidden = shown > 0;
+});
+</script>
+</body>
+</html>
+`))
+
+var catalogModeTemplate = template.Must(template.New("mode").Funcs(catalogFuncs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Name}} · Kilo Code Modes</title>
+` + catalogStyle + `
+</head>
+<body>
+<p><a href="../index.html">← All modes</a></p>
+<h1>{{emoji .IconName}} {{.Name}}</h1>
+<table>
+<tr><th>Slug</th><td><code>{{.Slug}}</code></td></tr>
+<tr><th>Icon</th><td><code>{{.IconName}}</code></td></tr>
+<tr><th>Description</th><td>{{.Description}}</td></tr>
+{{if .WhenToUse}}<tr><th>When to use</th><td>{{.WhenToUse}}</td></tr>
+{{end}}<tr><th>Groups</th><td>{{range .Groups}}<span class="group">{{.}}</span>{{end}}</td></tr>
+<tr><th>Source</th><td><code>{{.SourceFile}}</code>{{if .Plugin}} (plugin {{.Plugin}}){{end}}</td></tr>
+{{if .OriginalModel}}<tr><th>Model</th><td>{{.OriginalModel}}</td></tr>
+{{end}}</table>
+<h2>Role definition</h2>
+<pre>{{.RoleDefinition}}</pre>
+{{if .CustomInstructions}}<h2>Custom instructions</h2>
+<pre>{{.CustomInstructions}}</pre>
+{{end}}{{range .RuleFiles}}<h2>.kilocode/rules-{{$.Slug}}/{{.Name}}</h2>
+<pre>{{.Content}}</pre>
+{{end}}</body>
+</html>
+`))
+
+// writeHTMLCatalog writes index.html and modes/<slug>.html
+func writeHTMLCatalog(dir string, groups []CatalogGroup) error {
+	if err := renderCatalogPage(filepath.Join(dir, "index.html"), catalogIndexTemplate, groups); err != nil {
+		return err
+	}
+	for _, group := range groups {
+		for _, mode := range group.Modes {
+			if err := renderCatalogPage(filepath.Join(dir, "modes", mode.Slug+".html"), catalogModeTemplate, mode); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// renderCatalogPage executes a catalog template into a file
+func renderCatalogPage(path string, tmpl *template.Template, data interface{}) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write catalog page: %w", err)
+	}
+	defer file.Close()
+	if err := tmpl.Execute(file, data); err != nil {
+		return fmt.Errorf("failed to render %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// writeMarkdownCatalog writes README.md and modes/<slug>.md
+func writeMarkdownCatalog(dir string, groups []CatalogGroup) error {
+	var content strings.Builder
+	content.WriteString("# Kilo Code Modes\n\n")
+	total := 0
+	for _, group := range groups {
+		total += len(group.Modes)
+	}
+	content.WriteString(fmt.Sprintf("%d modes converted from Claude agents.\n\n", total))
+
+	for _, group := range groups {
+		content.WriteString(fmt.Sprintf("## %s\n\n", group.Name))
+		content.WriteString("| Mode | Icon | Description | When to use | Groups | Source | Model |\n")
+		content.WriteString("|------|------|-------------|-------------|--------|--------|-------|\n")
+		for _, mode := range group.Modes {
+			content.WriteString(fmt.Sprintf("| [%s](modes/%s.md) | %s `%s` | %s | %s | %s | `%s` | %s |\n",
+				markdownCell(mode.Name), mode.Slug, emojiFor(mode.IconName), mode.IconName, markdownCell(mode.Description),
+				markdownCell(mode.WhenToUse), strings.Join(mode.Groups, ", "), mode.SourceFile, markdownCell(mode.OriginalModel)))
+		}
+		content.WriteString("\n")
+
+		for _, mode := range group.Modes {
+			if err := os.WriteFile(filepath.Join(dir, "modes", mode.Slug+".md"), []byte(markdownModePage(mode)), 0644); err != nil {
+				return fmt.Errorf("failed to write catalog page: %w", err)
+			}
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte(content.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write catalog index: %w", err)
+	}
+	return nil
+}
+
+// markdownModePage renders the detail page of one mode
+func markdownModePage(mode KiloMode) string {
+	var content strings.Builder
+	content.WriteString("[← All modes](../README.md)\n\n")
+	content.WriteString(fmt.Sprintf("# %s %s\n\n", emojiFor(mode.IconName), mode.Name))
+	content.WriteString(fmt.Sprintf("- **Slug**: `%s`\n", mode.Slug))
+	content.WriteString(fmt.Sprintf("- **Icon**: `%s`\n", mode.IconName))
+	content.WriteString(fmt.Sprintf("- **Description**: %s\n", mode.Description))
+	if mode.WhenToUse != "" {
+		content.WriteString(fmt.Sprintf("- **When to use**: %s\n", mode.WhenToUse))
+	}
+	content.WriteString(fmt.Sprintf("- **Groups**: %s\n", strings.Join(mode.Groups, ", ")))
+	content.WriteString(fmt.Sprintf("- **Source**: `%s`\n", mode.SourceFile))
+	if mode.Plugin != "" {
+		content.WriteString(fmt.Sprintf("- **Plugin**: %s\n", mode.Plugin))
+	}
+	if mode.OriginalModel != "" {
+		content.WriteString(fmt.Sprintf("- **Model**: %s\n", mode.OriginalModel))
+	}
+
+	content.WriteString("\n## Role definition\n\n")
+	content.WriteString(mode.RoleDefinition + "\n")
+	if mode.CustomInstructions != "" {
+		content.WriteString("\n## Custom instructions\n\n")
+		content.WriteString(mode.CustomInstructions + "\n")
+	}
+	for _, rule := range mode.RuleFiles {
+		content.WriteString(fmt.Sprintf("\n## .kilocode/rules-%s/%s\n\n", mode.Slug, rule.Name))
+		content.WriteString(strings.TrimRight(rule.Content, "\n") + "\n")
+	}
+	return content.String()
+}
+
+// markdownCell keeps a value on one table row
+func markdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", `\|`)
+	return strings.Join(strings.Fields(value), " ")
+}
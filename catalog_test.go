@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConvertDirectory_Catalog(t *testing.T) {
+	input := t.TempDir()
+	files := map[string]string{
+		"ai-engineer.md":            "---\nname: ai-engineer\ndescription: Builds LLM apps <script>alert(1)</script>\nmodel: opus\n---\nYou build retrieval pipelines.\n",
+		"quality/test-automator.md": "---\nname: test-automator\ndescription: Writes test suites\n---\nWrite tests | then run them.\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(input, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	output := t.TempDir()
+	c := NewConverter()
+	c.catalog = []string{"html", "markdown"}
+	if err := c.convertDirectory(input, output, false, false); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(output, catalogDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	index := read("index.html")
+	for _, want := range []string{`id="search"`, `<h2>Top level</h2>`, `<h2>quality</h2>`, `href="modes/ai-engineer.html"`, "<td>opus</td>", "quality/test-automator.md"} {
+		if !strings.Contains(index, want) {
+			t.Errorf("Expected %q in index.html", want)
+		}
+	}
+	if strings.Contains(index, "<script>alert") || strings.Contains(index, "https://") {
+		t.Error("Expected an escaped, self-contained index.html")
+	}
+	if page := read("modes/test-automator.html"); !strings.Contains(page, "Write tests | then run them.") {
+		t.Errorf("Expected the full instructions on the detail page:\n%s", page)
+	}
+
+	readme := read("README.md")
+	if !strings.Contains(readme, "## quality") || !strings.Contains(readme, "[AI Engineer](modes/ai-engineer.md)") {
+		t.Errorf("Expected grouped links in README.md:\n%s", readme)
+	}
+	if page := read("modes/test-automator.md"); !strings.Contains(page, "## Custom instructions\n\nWrite tests | then run them.") {
+		t.Errorf("Expected the instructions on the Markdown page:\n%s", page)
+	}
+
+	if _, err := ParseCatalogFormats("html,pdf"); err == nil {
+		t.Error("Expected an unknown catalog format to be rejected")
+	}
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// classifierMinSimilarity is the cosine similarity below which the keyword rules decide instead
+const classifierMinSimilarity = 0.2
+
+//go:embed classifier_model.json
+var embeddedClassifierModel []byte
+
+// CategoryLabel is what every agent of a training category converts to
+type CategoryLabel struct {
+	Icon        string   `yaml:"icon" json:"icon"`
+	Groups      []string `yaml:"groups" json:"groups"` // Upper bound; capability evidence still decides each group
+	Description string   `yaml:"description" json:"description"`
+}
+
+// ClassifierCategory is a category's label and the TF-IDF centroid of its training agents
+type ClassifierCategory struct {
+	Name     string             `json:"name"`
+	Label    CategoryLabel      `json:"label"`
+	Centroid map[string]float64 `json:"centroid"`
+}
+
+// ClassifierModel is a TF-IDF nearest-centroid classifier over stemmed agent words
+type ClassifierModel struct {
+	Documents  int                  `json:"documents"`
+	IDF        map[string]float64   `json:"idf"`
+	Categories []ClassifierCategory `json:"categories"`
+}
+
+// Classification is the category an agent is closest to
+type Classification struct {
+	Category   *ClassifierCategory
+	Similarity float64
+	Candidates []CandidateScore // Every category, by similarity in hundredths
+}
+
+// stopWords carry no signal about what an agent does
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "the": true, "to": true, "of": true, "for": true,
+	"in": true, "on": true, "with": true, "you": true, "your": true, "are": true, "is": true,
+	"it": true, "that": true, "this": true, "be": true, "or": true, "as": true, "by": true,
+	"each": true, "every": true, "then": true, "them": true, "they": true, "can": true,
+	"use": true, "when": true, "agent": true, "keep": true, "we": true, "our": true,
+}
+
+// LoadClassifierModel reads a model written by the train subcommand; "" selects the embedded model
+func LoadClassifierModel(path string) (*ClassifierModel, error) {
+	data := embeddedClassifierModel
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("error reading classifier model %s: %w", path, err)
+		}
+	}
+	var model ClassifierModel
+	if err := json.Unmarshal(data, &model); err != nil {
+		return nil, fmt.Errorf("invalid classifier model: %w", err)
+	}
+	if len(model.Categories) == 0 {
+		return nil, fmt.Errorf("classifier model has no categories")
+	}
+	return &model, nil
+}
+
+// termFrequencies counts an agent's meaningful words, weighting each by the region it appears in
+func termFrequencies(text *AnalysisText) map[string]float64 {
+	tf := make(map[string]float64)
+	for _, region := range text.Regions {
+		for _, token := range region.Tokens {
+			if !stopWords[token] && len(token) > 1 {
+				tf[token] += float64(region.Weight)
+			}
+		}
+	}
+	return tf
+}
+
+// vector turns term frequencies into an L2-normalized TF-IDF vector, ignoring unknown words
+func (m *ClassifierModel) vector(tf map[string]float64) map[string]float64 {
+	v := make(map[string]float64)
+	for term, freq := range tf {
+		if idf, ok := m.IDF[term]; ok {
+			v[term] = (1 + math.Log(freq)) * idf
+		}
+	}
+	return normalize(v)
+}
+
+// Classify finds the category whose centroid is most similar to the agent
+func (m *ClassifierModel) Classify(text *AnalysisText) Classification {
+	v := m.vector(termFrequencies(text))
+
+	var result Classification
+	for i := range m.Categories {
+		category := &m.Categories[i]
+		similarity := cosine(v, category.Centroid)
+		result.Candidates = append(result.Candidates, CandidateScore{
+			Value:   category.Name,
+			Score:   int(math.Round(similarity * 100)),
+			Matches: sharedTerms(v, category.Centroid),
+		})
+		if similarity > result.Similarity {
+			result.Category, result.Similarity = category, similarity
+		}
+	}
+	result.Candidates = rankCandidates(result.Candidates)
+	return result
+}
+
+// Confident reports whether the classification is strong enough to override the keyword rules
+func (c Classification) Confident() bool {
+	return c.Category != nil && c.Similarity >= classifierMinSimilarity
+}
+
+// trace explains a classification-based decision for one field
+func (c Classification) trace(field, value string) *DecisionTrace {
+	return &DecisionTrace{
+		Field:      field,
+		Value:      value,
+		Rule:       fmt.Sprintf("classifier category %s (similarity %.2f)", c.Category.Name, c.Similarity),
+		Candidates: c.Candidates,
+	}
+}
+
+// sharedTerms lists the strongest words an agent shares with a category centroid
+func sharedTerms(v, centroid map[string]float64) []KeywordMatch {
+	type term struct {
+		word   string
+		weight float64
+	}
+	var shared []term
+	for word, weight := range v {
+		if centroid[word] > 0 {
+			shared = append(shared, term{word, weight * centroid[word]})
+		}
+	}
+	sort.Slice(shared, func(i, j int) bool {
+		if shared[i].weight != shared[j].weight {
+			return shared[i].weight > shared[j].weight
+		}
+		return shared[i].word < shared[j].word
+	})
+
+	var matches []KeywordMatch
+	for _, t := range shared[:min(len(shared), 3)] {
+		matches = append(matches, KeywordMatch{Keyword: t.word, Count: 1, Weight: int(math.Round(t.weight * 100))})
+	}
+	return matches
+}
+
+// TrainClassifier builds a model from a labeled corpus: categories.yaml maps each
+// category to its label, and training/<category>/ holds that category's agents
+func (c *Converter) TrainClassifier(corpusDir string) (*ClassifierModel, error) {
+	data, err := os.ReadFile(filepath.Join(corpusDir, "categories.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("error reading labels: %w", err)
+	}
+	var labels struct {
+		Categories map[string]CategoryLabel `yaml:"categories"`
+	}
+	if err := yaml.Unmarshal(data, &labels); err != nil {
+		return nil, fmt.Errorf("invalid labels: %w", err)
+	}
+
+	// Term frequencies of every training agent, by category
+	docs := make(map[string][]map[string]float64)
+	documentFrequency := make(map[string]int)
+	total := 0
+	for _, name := range sortedKeys(labels.Categories) {
+		err := filepath.WalkDir(filepath.Join(corpusDir, name), func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !isCandidateFile(d.Name()) {
+				return err
+			}
+			agents, err := c.readSource(path)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			for _, agent := range agents {
+				tf := termFrequencies(newAnalysisText(agent.Name, agent.Description, agent.Body))
+				docs[name] = append(docs[name], tf)
+				for term := range tf {
+					documentFrequency[term]++
+				}
+				total++
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(docs[name]) == 0 {
+			return nil, fmt.Errorf("category %s has no training agents in %s", name, filepath.Join(corpusDir, name))
+		}
+	}
+
+	model := &ClassifierModel{Documents: total, IDF: make(map[string]float64)}
+	for term, df := range documentFrequency {
+		model.IDF[term] = round4(math.Log(float64(1+total)/float64(1+df)) + 1)
+	}
+
+	// Each centroid is the normalized mean of its agents' vectors
+	for _, name := range sortedKeys(labels.Categories) {
+		centroid := make(map[string]float64)
+		for _, tf := range docs[name] {
+			for term, weight := range model.vector(tf) {
+				centroid[term] += weight / float64(len(docs[name]))
+			}
+		}
+		centroid = normalize(centroid)
+		for term, weight := range centroid {
+			centroid[term] = round4(weight)
+		}
+		model.Categories = append(model.Categories, ClassifierCategory{Name: name, Label: labels.Categories[name], Centroid: centroid})
+	}
+	return model, nil
+}
+
+// SaveClassifierModel writes a model as indented JSON with sorted keys, so retraining diffs cleanly
+func SaveClassifierModel(model *ClassifierModel, path string) error {
+	data, err := json.MarshalIndent(model, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// normalize scales a vector to unit length
+func normalize(v map[string]float64) map[string]float64 {
+	var norm float64
+	for _, weight := range v {
+		norm += weight * weight
+	}
+	if norm == 0 {
+		return v
+	}
+	norm = math.Sqrt(norm)
+	for term := range v {
+		v[term] /= norm
+	}
+	return v
+}
+
+// cosine returns the cosine similarity of two unit vectors
+func cosine(a, b map[string]float64) float64 {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	var dot float64
+	for term, weight := range a {
+		dot += weight * b[term]
+	}
+	return dot
+}
+
+// round4 rounds to four decimal places to keep the model file small and stable
+func round4(x float64) float64 {
+	return math.Round(x*10000) / 10000
+}
+
+// runTrain implements the train subcommand
+func runTrain(args []string) error {
+	flags := flag.NewFlagSet("train", flag.ExitOnError)
+	corpus := flags.String("corpus", "training", "Directory with categories.yaml and one subdirectory of agents per category")
+	output := flags.String("output", "classifier_model.json", "Where to write the trained model")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s train [options]\n\nRebuild the classifier model from a labeled agent corpus.\n\nOptions:\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	model, err := NewConverter().TrainClassifier(*corpus)
+	if err != nil {
+		return err
+	}
+	if err := SaveClassifierModel(model, *output); err != nil {
+		return fmt.Errorf("error writing model: %w", err)
+	}
+	fmt.Printf("✓ Trained %d categories from %d agents → %s\n", len(model.Categories), model.Documents, *output)
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestEmbeddedModel_MatchesCorpus fails when training/ changes without rerunning "train"
+func TestEmbeddedModel_MatchesCorpus(t *testing.T) {
+	model, err := NewConverter().TrainClassifier("training")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := json.MarshalIndent(model, "", "  ")
+	if string(append(want, '\n')) != string(embeddedClassifierModel) {
+		t.Error("classifier_model.json is stale; run: go run . train")
+	}
+}
+
+func TestClassify_HeldOutAgents(t *testing.T) {
+	model, err := LoadClassifierModel("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]struct{ name, description string }{
+		"frontend": {"svelte-dev", "Builds Svelte components and responsive web pages with CSS."},
+		"database": {"postgres-tuner", "Optimizes slow Postgres queries and adds missing indexes."},
+		"security": {"pentest-helper", "Finds vulnerabilities and insecure authentication in web code."},
+		"legal":    {"gdpr-advisor", "Drafts GDPR privacy policies and compliance notes."},
+	}
+	for want, agent := range cases {
+		result := model.Classify(newAnalysisText(agent.name, agent.description, ""))
+		if !result.Confident() || result.Category.Name != want {
+			t.Errorf("%s: expected %s, got %+v", agent.name, want, result.Candidates[:3])
+		}
+	}
+}
+
+func TestBuildMode_ClassifierFallsBackToKeywords(t *testing.T) {
+	c := NewConverter()
+	var err error
+	if c.classifier, err = LoadClassifierModel(""); err != nil {
+		t.Fatal(err)
+	}
+
+	mode := c.buildMode(&SourceAgent{Name: "api-designer", Description: "Designs REST APIs and GraphQL schemas for backend services."})
+	if trace := findTrace(mode.Traces, "iconName"); mode.IconName != "codicon-server" || !strings.HasPrefix(trace.Rule, "classifier category backend") {
+		t.Errorf("Expected the backend category, got %s (%s)", mode.IconName, trace.Rule)
+	}
+
+	mode = c.buildMode(&SourceAgent{Name: "zz", Description: "Qwerty zxcvb."})
+	for _, trace := range mode.Traces {
+		if strings.HasPrefix(trace.Rule, "classifier") {
+			t.Errorf("Expected keyword rules for an unrecognizable agent, got %s: %s", trace.Field, trace.Rule)
+		}
+	}
+}
+
+func TestBuildMode_ClassifierNeverGrantsGroups(t *testing.T) {
+	c := NewConverter()
+	var err error
+	if c.classifier, err = LoadClassifierModel(""); err != nil {
+		t.Fatal(err)
+	}
+
+	// The ai-ml category allows every group, but nothing in the agent asks for more than read
+	mode := c.buildMode(&SourceAgent{Name: "llm-advisor", Description: "Advises on LLM prompt design, RAG retrieval and embedding models."})
+	if trace := findTrace(mode.Traces, "description"); !strings.HasPrefix(trace.Rule, "classifier category ai-ml") {
+		t.Fatalf("Expected the ai-ml category, got %s", trace.Rule)
+	}
+	if got := strings.Join(mode.Groups, ", "); got != "read" {
+		t.Errorf("Expected read only without capability evidence, got %s", got)
+	}
+}
+
+// findTrace returns the trace recorded for a field, or an empty one
+func findTrace(traces []DecisionTrace, field string) DecisionTrace {
+	for _, trace := range traces {
+		if trace.Field == field {
+			return trace
+		}
+	}
+	return DecisionTrace{}
+}
+
+func TestTrainClassifier_EmptyCategory(t *testing.T) {
+	dir := t.TempDir()
+	labels := "categories:\n  empty:\n    icon: codicon-gear\n    groups: [read]\n    description: Nothing\n"
+	if err := os.WriteFile(filepath.Join(dir, "categories.yaml"), []byte(labels), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Mkdir(filepath.Join(dir, "empty"), 0755)
+	if _, err := NewConverter().TrainClassifier(dir); err == nil || !strings.Contains(err.Error(), "no training agents") {
+		t.Errorf("Expected an empty-category error, got %v", err)
+	}
+}
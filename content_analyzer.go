@@ -6,6 +6,9 @@ import (
 	"strings"
 )
 
+// fallbackDescription is the short description used when no pattern matches
+const fallbackDescription = "Development specialist"
+
 // NewContentAnalyzer creates a new content analyzer with predefined patterns
 func NewContentAnalyzer() *ContentAnalyzer {
 	return &ContentAnalyzer{
@@ -77,31 +80,31 @@ func NewContentAnalyzer() *ContentAnalyzer {
 }
 
 // findBestMatch finds the best matching pattern and returns its description
-func (ca *ContentAnalyzer) findBestMatch(text string, patterns map[string]string) (string, int) {
-	bestMatch := ""
-	bestScore := 0
-
-	for pattern, description := range patterns {
-		keywords := strings.Split(pattern, "|")
-		score := 0
+func (ca *ContentAnalyzer) findBestMatch(text *AnalysisText, patterns map[string]string) (string, int) {
+	ranked := ca.rankPatterns(text, patterns)
+	if len(ranked) == 0 || ranked[0].Score == 0 {
+		return "", 0
+	}
+	return patterns[ranked[0].Value], ranked[0].Score
+}
 
-		for _, keyword := range keywords {
+// rankPatterns scores every pattern against the text, best first. Ties keep the
+// alphabetical order of the patterns so results are stable.
+func (ca *ContentAnalyzer) rankPatterns(text *AnalysisText, patterns map[string]string) []CandidateScore {
+	var candidates []CandidateScore
+	for _, pattern := range sortedKeys(patterns) {
+		candidate := CandidateScore{Value: pattern}
+		for _, keyword := range strings.Split(pattern, "|") {
+			// Weight matches by region, frequency and keyword length
 			keyword = strings.TrimSpace(keyword)
-			if strings.Contains(text, keyword) {
-				// Weight matches by frequency and keyword length
-				frequency := strings.Count(text, keyword)
-				keywordWeight := len(keyword) // Longer keywords get higher weight
-				score += frequency * keywordWeight
+			for _, match := range text.matches(keyword) {
+				candidate.Score += match.Weight * match.Count * len(keyword) // Longer keywords get higher weight
+				candidate.Matches = append(candidate.Matches, match)
 			}
 		}
-
-		if score > bestScore {
-			bestScore = score
-			bestMatch = description
-		}
+		candidates = append(candidates, candidate)
 	}
-
-	return bestMatch, bestScore
+	return rankCandidates(candidates)
 }
 
 // extractFromProactiveStatement attempts to extract existing "Use PROACTIVELY for..." statements
@@ -115,48 +118,66 @@ func (ca *ContentAnalyzer) extractFromProactiveStatement(description string) str
 	return ""
 }
 
-// generateWhenToUseStatement creates a comprehensive "when to use" statement
-func (ca *ContentAnalyzer) generateWhenToUseStatement(name, description, content string) string {
+// generateWhenToUseStatement creates a comprehensive "when to use" statement and traces the patterns behind it
+func (ca *ContentAnalyzer) generateWhenToUseStatement(description string, allText *AnalysisText) (string, *DecisionTrace) {
+	trace := &DecisionTrace{Field: "whenToUse"}
+
 	// First, try to extract from existing "Use PROACTIVELY for..." statements
 	if proactiveStatement := ca.extractFromProactiveStatement(description); proactiveStatement != "" {
-		return proactiveStatement + "."
+		trace.Value = proactiveStatement + "."
+		trace.Rule = `"Use PROACTIVELY for …" in the description`
+		return trace.Value, trace
 	}
 
-	// Combine all text for analysis
-	allText := strings.ToLower(fmt.Sprintf("%s %s %s", name, description, content))
-
-	// Find primary use case from role patterns
-	primaryUse, primaryScore := ca.findBestMatch(allText, ca.rolePatterns)
-
-	// Find domain specialization
-	domainSpec, domainScore := ca.findBestMatch(allText, ca.domainPatterns)
+	roles := ca.rankPatterns(allText, ca.rolePatterns)
+	domains := ca.rankPatterns(allText, ca.domainPatterns)
+	actions := ca.rankPatterns(allText, ca.actionPatterns)
+
+	// Every scored pattern is a candidate, labelled with the table it came from
+	for _, ranking := range []struct {
+		kind       string
+		candidates []CandidateScore
+	}{{"role", roles}, {"domain", domains}, {"action", actions}} {
+		for _, candidate := range ranking.candidates {
+			candidate.Value = ranking.kind + ": " + candidate.Value
+			trace.Candidates = append(trace.Candidates, candidate)
+		}
+	}
+	trace.Candidates = rankCandidates(trace.Candidates)
 
-	// Find action patterns
-	actionPattern, actionScore := ca.findBestMatch(allText, ca.actionPatterns)
+	// Primary use case, domain specialization and action pattern are each the best of their table
+	primaryUse, primaryScore := ca.rolePatterns[roles[0].Value], roles[0].Score
+	domainSpec, domainScore := ca.domainPatterns[domains[0].Value], domains[0].Score
+	actionPattern, actionScore := ca.actionPatterns[actions[0].Value], actions[0].Score
 
 	// Build the statement
 	var statement strings.Builder
 
 	if primaryUse != "" && primaryScore > 0 {
 		statement.WriteString(fmt.Sprintf("Use this mode %s", primaryUse))
+		trace.Rule = fmt.Sprintf("role pattern %s (score %d)", roles[0].Value, primaryScore)
 	} else if actionPattern != "" && actionScore > 0 {
 		statement.WriteString(fmt.Sprintf("Use this mode when you need %s", actionPattern))
+		trace.Rule = fmt.Sprintf("action pattern %s (score %d)", actions[0].Value, actionScore)
 	} else {
 		statement.WriteString(fmt.Sprintf("Use this mode for %s", ca.fallbackPattern))
+		trace.Rule = "generic statement"
+		trace.Fallback = "no pattern matched; used the generic fallback statement"
 	}
 
 	// Add domain specialization if found and significant, but avoid duplication
 	if domainSpec != "" && domainScore > 5 && !strings.Contains(primaryUse, "Specialized in") && !strings.Contains(primaryUse, strings.Split(domainSpec, " ")[2]) {
 		statement.WriteString(fmt.Sprintf(". %s", domainSpec))
+		trace.Rule += fmt.Sprintf(", plus domain pattern %s (score %d > 5)", domains[0].Value, domainScore)
 	}
 
-	return statement.String() + "."
+	trace.Value = statement.String() + "."
+	return trace.Value, trace
 }
 
-// generateDescription creates a short description for the agent using ContentAnalyzer
-func generateDescription(name, description, content string) string {
+// generateDescription creates a short description for the agent using ContentAnalyzer and traces the pattern behind it
+func generateDescription(text *AnalysisText) (string, *DecisionTrace) {
 	analyzer := NewContentAnalyzer()
-	text := strings.ToLower(fmt.Sprintf("%s %s %s", name, description, content))
 
 	// Create mapping from patterns to concise descriptions (3-5 words)
 	shortDescriptions := map[string]string{
@@ -183,33 +204,27 @@ func generateDescription(name, description, content string) string {
 		"database|sql|nosql|mongodb|postgres|mysql":                                    "Database management",
 	}
 
+	trace := &DecisionTrace{Field: "description"}
+
 	// Find best match from role patterns first (higher priority)
-	bestDescription := ""
-	bestScore := 0
-
-	for pattern, desc := range shortDescriptions {
-		_, score := analyzer.findBestMatch(text, map[string]string{pattern: desc})
-		if score > bestScore {
-			bestScore = score
-			bestDescription = desc
-		}
+	trace.Candidates = analyzer.rankPatterns(text, shortDescriptions)
+	if best := trace.Candidates[0]; best.Score > 0 {
+		trace.Value = shortDescriptions[best.Value]
+		trace.Rule = fmt.Sprintf("role pattern %s (score %d)", best.Value, best.Score)
+		return trace.Value, trace
 	}
 
 	// If no strong role match, try domain patterns
-	if bestScore == 0 {
-		for pattern, desc := range domainDescriptions {
-			_, score := analyzer.findBestMatch(text, map[string]string{pattern: desc})
-			if score > bestScore {
-				bestScore = score
-				bestDescription = desc
-			}
-		}
+	trace.Candidates = analyzer.rankPatterns(text, domainDescriptions)
+	if best := trace.Candidates[0]; best.Score > 0 {
+		trace.Value = domainDescriptions[best.Value]
+		trace.Rule = fmt.Sprintf("domain pattern %s (score %d)", best.Value, best.Score)
+		return trace.Value, trace
 	}
 
 	// Fallback to default if no matches
-	if bestDescription == "" {
-		bestDescription = "Development specialist"
-	}
-
-	return bestDescription
+	trace.Value = fallbackDescription
+	trace.Rule = "default description"
+	trace.Fallback = fmt.Sprintf("no pattern matched; used %q", fallbackDescription)
+	return trace.Value, trace
 }
@@ -15,7 +15,7 @@ func TestFindBestMatch(t *testing.T) {
 	ca := NewContentAnalyzer()
 	patterns := map[string]string{"foo|bar": "desc1", "baz": "desc2"}
 	text := "this is a foo bar test"
-	desc, score := ca.findBestMatch(text, patterns)
+	desc, score := ca.findBestMatch(plainAnalysisText(text), patterns)
 	if desc != "desc1" || score == 0 {
 		t.Errorf("Expected desc1 and nonzero score, got %q, %d", desc, score)
 	}
@@ -31,7 +31,7 @@ func TestExtractFromProactiveStatement(t *testing.T) {
 
 func TestGenerateWhenToUseStatement_Proactive(t *testing.T) {
 	ca := NewContentAnalyzer()
-	out := ca.generateWhenToUseStatement("", "Use PROACTIVELY for code review.", "")
+	out, _ := ca.generateWhenToUseStatement("Use PROACTIVELY for code review.", newAnalysisText("", "Use PROACTIVELY for code review.", ""))
 	if out == "" || out == ca.fallbackPattern+"." {
 		t.Error("Expected a specific when-to-use statement, got fallback")
 	}
@@ -39,14 +39,14 @@ func TestGenerateWhenToUseStatement_Proactive(t *testing.T) {
 
 func TestGenerateWhenToUseStatement_RolePattern(t *testing.T) {
 	ca := NewContentAnalyzer()
-	out := ca.generateWhenToUseStatement("", "This is for debugging and troubleshooting.", "")
+	out, _ := ca.generateWhenToUseStatement("This is for debugging and troubleshooting.", newAnalysisText("", "This is for debugging and troubleshooting.", ""))
 	if out == "" || out == ca.fallbackPattern+"." {
 		t.Error("Expected a specific when-to-use statement, got fallback")
 	}
 }
 
 func TestGenerateDescription(t *testing.T) {
-	desc := generateDescription("AI Engineer", "", "")
+	desc, _ := generateDescription(newAnalysisText("AI Engineer", "", ""))
 	if desc == "" || desc == "Development specialist" {
 		t.Error("Expected a specific short description, got fallback")
 	}
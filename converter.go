@@ -1,8 +1,8 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"os"
 	"regexp"
 	"strings"
 
@@ -11,314 +11,396 @@ import (
 
 // NewConverter creates a new converter instance
 func NewConverter() *Converter {
-	return &Converter{
+	c := &Converter{
 		modelMapping: map[string]string{
 			"opus":   "anthropic/claude-opus-3",
 			"sonnet": "anthropic/claude-sonnet-3.5",
 			"haiku":  "anthropic/claude-haiku-3",
 		},
-		defaultGroups: map[string][]string{
-			"full":      {"read", "edit", "browser", "command", "mcp"},
-			"review":    {"read", "edit"},
-			"architect": {"read", "edit"},
-			"web":       {"read", "edit", "browser", "command"},
-			"system":    {"read", "edit", "command"},
-			"default":   {"read", "edit", "browser", "command"},
-		},
 		frontmatterRe:   regexp.MustCompile(`(?s)^---\n(.*?)\n---\s*\n(.*)$`),
-		slugRe:          regexp.MustCompile(`[^a-z0-9]+`),
 		iconSelector:    NewIconSelector(),
 		contentAnalyzer: NewContentAnalyzer(),
 		yamlSanitizer:   NewYAMLSanitizer(),
+		outputFormat:    "yaml",
+		claudeExtras:    "none",
+		examples:        "when-to-use",
+	}
+	c.readers = []Reader{
+		&rooReader{},
+		&copilotReader{converter: c},
+		&cursorReader{converter: c},
+		&claudeReader{converter: c},
 	}
+	return c
 }
 
 // parseFrontmatter extracts YAML frontmatter and markdown content
 func (c *Converter) parseFrontmatter(content string) (*ClaudeAgent, string, error) {
-	// Normalize line endings
-	content = strings.ReplaceAll(content, "\r\n", "\n")
-	content = strings.ReplaceAll(content, "\r", "\n")
-	trimmed := strings.TrimSpace(content)
-
-	lines := strings.Split(trimmed, "\n")
-	if len(lines) < 3 || lines[0] != "---" {
-		return nil, "", fmt.Errorf("no valid YAML frontmatter found - first line: %q", lines[0])
-	}
-
-	// Find the closing ---
-	var yamlEnd int
-	for i := 1; i < len(lines); i++ {
-		if lines[i] == "---" {
-			yamlEnd = i
-			break
-		}
-	}
-
-	if yamlEnd == 0 {
-		return nil, "", fmt.Errorf("no closing --- found")
-	}
+	agent, markdown, _, err := c.parseFrontmatterWithStats(content)
+	return agent, markdown, err
+}
 
-	yamlContent := strings.Join(lines[1:yamlEnd], "\n")
-	markdownContent := strings.Join(lines[yamlEnd+1:], "\n")
+// generateSlug creates a URL-friendly slug from the agent name, transliterating non-Latin letters
+func (c *Converter) generateSlug(name string) string {
+	return slugify(name)
+}
 
-	var agent ClaudeAgent
-	err := yaml.Unmarshal([]byte(yamlContent), &agent)
+// determineFileRestrictions sets file access restrictions based on agent type
+func (c *Converter) determineFileRestrictions(text *AnalysisText) (string, string) {
+	// Architect modes typically only edit markdown files
+	if text.contains("architect") && text.containsAny("review", "reviewer") {
+		return "\\.md$", "Markdown files only"
+	}
 
-	if err != nil {
-		// Try sanitization
-		sanitizedYAML, sanitizeErr := c.yamlSanitizer.SanitizeFrontmatter(yamlContent)
-		if sanitizeErr != nil {
-			return nil, "", fmt.Errorf("YAML parsing failed, sanitization also failed: original error: %w, sanitization error: %v", err, sanitizeErr)
-		}
+	return "", ""
+}
 
-		// Retry with sanitized content
-		err = yaml.Unmarshal([]byte(sanitizedYAML), &agent)
-		if err != nil {
-			return nil, "", fmt.Errorf("YAML parsing failed even after sanitization: %w", err)
-		}
+// generateWhenToUse creates a description of when to use this mode using intelligent content analysis
+func (c *Converter) generateWhenToUse(description string, analysis *AnalysisText) (string, *DecisionTrace) {
+	return c.contentAnalyzer.generateWhenToUseStatement(description, analysis)
+}
 
-		// Log successful sanitization
-		fmt.Printf("  ⚠ Applied YAML sanitization\n")
+// convertAgent converts a single-agent source file to a Kilo Code mode
+func (c *Converter) convertAgent(filePath string) (*KiloMode, error) {
+	modes, _, err := c.convertSource(filePath)
+	if len(modes) == 0 {
+		return nil, err
 	}
+	return &modes[0], nil
+}
 
-	if agent.Name == "" {
-		return nil, "", fmt.Errorf("missing required 'name' field")
+// convertSource converts every agent found in a source file and returns the sanitization applied, if any.
+// Modes denied by policy or rejected by strict mode are left out and returned joined as the error.
+func (c *Converter) convertSource(filePath string) ([]KiloMode, *SanitizationRecord, error) {
+	agents, err := c.readSource(filePath)
+	if err != nil {
+		return nil, nil, err
 	}
-	if agent.Description == "" {
-		return nil, "", fmt.Errorf("missing required 'description' field")
+
+	var modes []KiloMode
+	var sanitized *SanitizationRecord
+	var rejected []error
+	for _, agent := range agents {
+		mode := c.buildMode(agent)
+		if len(agents) > 1 {
+			mode.Slug = c.modeSlug(agent, true)
+		}
+		// Policies and strict mode see the final, namespaced slug
+		if plugin := findPlugin(c.plugins, filePath); plugin != nil {
+			plugin.namespace(c, mode)
+		}
+		if agent.Sanitization != nil {
+			sanitized = agent.Sanitization
+		}
+		if c.policy != nil {
+			if err := c.policy.Apply(agent, mode, filePath); err != nil {
+				rejected = append(rejected, err)
+				continue
+			}
+			for _, violation := range mode.Violations {
+				fmt.Printf("  ⚠ Policy %s (%s): %s\n", violation.Rule, violation.Action, violation.Message)
+			}
+		}
+		if c.strict && len(mode.Losses) > 0 {
+			rejected = append(rejected, &StrictModeError{Slug: mode.Slug, Losses: mode.Losses})
+			continue
+		}
+		modes = append(modes, *mode)
 	}
 
-	return &agent, strings.TrimSpace(markdownContent), nil
+	return modes, sanitized, errors.Join(rejected...)
 }
 
-// generateSlug creates a URL-friendly slug from the agent name
-func (c *Converter) generateSlug(name string) string {
-	slug := strings.ToLower(name)
-	slug = c.slugRe.ReplaceAllString(slug, "-")
-	slug = strings.Trim(slug, "-")
-	return slug
+// rejectedModes splits an error from convertSource into one error per rejected mode
+func rejectedModes(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
 }
 
-// determineGroups selects appropriate tool groups based on agent characteristics
-func (c *Converter) determineGroups(name, description, content string) []string {
-	text := strings.ToLower(fmt.Sprintf("%s %s %s", name, description, content))
+// buildMode maps a format-neutral agent onto a Kilo Code mode
+func (c *Converter) buildMode(agent *SourceAgent) *KiloMode {
+	markdown := agent.Body
 
-	// Review-only agents (code reviewers, auditors)
-	if (strings.Contains(text, "review") || strings.Contains(text, "reviewer") || strings.Contains(text, "audit")) &&
-		!strings.Contains(text, "architect") {
-		return c.defaultGroups["review"]
-	}
+	slug := c.modeSlug(agent, false)
 
-	// Architect modes typically only edit markdown files
-	if strings.Contains(text, "architect") && strings.Contains(text, "review") {
-		return c.defaultGroups["architect"]
-	}
+	// Every heuristic reads the same preprocessed prose, so code samples and URLs don't skew them
+	analysis := newAnalysisText(agent.Name, agent.Description, markdown)
 
-	// Web/frontend development (needs browser for testing)
-	if strings.Contains(text, "frontend") || strings.Contains(text, "react") || strings.Contains(text, "ui") ||
-		strings.Contains(text, "css") || strings.Contains(text, "html") || strings.Contains(text, "web") {
-		return c.defaultGroups["web"]
+	// Heuristic fallbacks are recorded so strict mode can refuse them, and every
+	// heuristic decision is traced for -explain and the JSON report
+	var fallbacks []ConversionLoss
+	var traces []DecisionTrace
+	traced := func(trace *DecisionTrace) {
+		traces = append(traces, *trace)
+		if trace.Fallback != "" {
+			fallbacks = append(fallbacks, ConversionLoss{trace.Field, trace.Fallback})
+		}
 	}
 
-	// System/backend development (needs command line tools)
-	if strings.Contains(text, "backend") || strings.Contains(text, "api") || strings.Contains(text, "server") ||
-		strings.Contains(text, "database") || strings.Contains(text, "devops") || strings.Contains(text, "deploy") ||
-		strings.Contains(text, "infrastructure") || strings.Contains(text, "cloud") || strings.Contains(text, "system") {
-		return c.defaultGroups["system"]
-	}
+	// The enricher, when configured, writes the descriptive fields the heuristics only guess at
+	enrichment := c.enrich(agent)
 
-	// AI/ML engineers and complex development (full access including MCP)
-	if strings.Contains(text, "ai") || strings.Contains(text, "llm") || strings.Contains(text, "ml") ||
-		strings.Contains(text, "data") || strings.Contains(text, "analytics") || strings.Contains(text, "engineer") ||
-		strings.Contains(text, "rag") || strings.Contains(text, "vector") || strings.Contains(text, "embedding") {
-		return c.defaultGroups["full"]
+	// A confident classifier match sets icon, groups and description; keyword rules are the fallback
+	var category *CategoryLabel
+	var classification Classification
+	if c.classifier != nil {
+		if classification = c.classifier.Classify(analysis); classification.Confident() {
+			category = &classification.Category.Label
+		}
 	}
 
-	// Default for most other cases
-	return c.defaultGroups["default"]
-}
-
-// determineFileRestrictions sets file access restrictions based on agent type
-func (c *Converter) determineFileRestrictions(name, description, content string) (string, string) {
-	text := strings.ToLower(fmt.Sprintf("%s %s %s", name, description, content))
+	groups := agent.Groups
+	var groupsTrace *DecisionTrace
+	if len(groups) == 0 {
+		var tools []string
+		if agent.Format == "claude" {
+			tools = agent.Tools
+		}
+		groups, groupsTrace = c.determineGroups(analysis, tools)
+		// A category's groups are an upper bound; only capability evidence grants a group
+		if category != nil && len(category.Groups) > 0 {
+			var outside []string
+			groups, outside = intersectGroups(groups, category.Groups)
+			if len(outside) > 0 {
+				groupsTrace.Value = strings.Join(groups, ", ")
+				groupsTrace.Rule += fmt.Sprintf("; classifier category %s does not allow %s", classification.Category.Name, strings.Join(outside, ", "))
+			}
+		}
+	}
 
-	// Architect modes typically only edit markdown files
-	if strings.Contains(text, "architect") && strings.Contains(text, "review") {
-		return "\\.md$", "Markdown files only"
+	// The -max-groups policy caps every mode, whatever decided its groups
+	groups, removed := c.capGroups(groups)
+	var capped []ConversionLoss
+	if len(removed) > 0 {
+		if groupsTrace == nil {
+			groupsTrace = &DecisionTrace{Field: "groups", Rule: "groups given by the source"}
+		}
+		groupsTrace.Value = strings.Join(groups, ", ")
+		groupsTrace.Rule += fmt.Sprintf("; -max-groups removed %s", strings.Join(removed, ", "))
+		for _, group := range removed {
+			capped = append(capped, ConversionLoss{"groups", fmt.Sprintf("%s removed by -max-groups", group)})
+		}
+	}
+	if groupsTrace != nil {
+		traced(groupsTrace)
+	}
+	fileRegex, fileDesc := agent.FileRegex, agent.FileRegexDescription
+	if fileRegex == "" {
+		fileRegex, fileDesc = c.determineFileRestrictions(analysis)
 	}
 
-	return "", ""
-}
+	// Generate icon and description
+	iconName := agent.IconName
+	var iconCandidates []CandidateScore
+	if iconName == "" {
+		var trace *DecisionTrace
+		if category != nil && c.iconSelector.validIcons[category.Icon] {
+			iconName, trace = category.Icon, classification.trace("iconName", category.Icon)
+		} else {
+			iconName, trace = c.iconSelector.selectIcon(analysis)
+		}
+		traced(trace)
+		if c.uniqueIcons {
+			iconCandidates = c.iconCandidates(trace, analysis)
+		}
+	}
+	shortDescription := agent.Summary
+	if shortDescription == "" {
+		var trace *DecisionTrace
+		if enrichment.Description != "" {
+			shortDescription, trace = enrichment.Description, enricherTrace("description", enrichment.Description)
+		} else if category != nil && category.Description != "" {
+			shortDescription, trace = category.Description, classification.trace("description", category.Description)
+		} else {
+			shortDescription, trace = generateDescription(analysis)
+		}
+		traced(trace)
+	}
 
-// generateWhenToUse creates a description of when to use this mode using intelligent content analysis
-func (c *Converter) generateWhenToUse(name, description, content string) string {
-	return c.contentAnalyzer.generateWhenToUseStatement(name, description, content)
-}
+	nameFormatter := c.nameFormatterFor(agent.SourcePath)
+	formattedName := agent.DisplayName
+	if formattedName == "" {
+		formattedName = nameFormatter.Format(agent.Name)
+	}
 
-// convertAgent converts a Claude Code sub-agent to Kilo Code mode
-func (c *Converter) convertAgent(filePath string) (*KiloMode, error) {
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("error reading file %s: %w", filePath, err)
+	// <example> blocks are routing hints, not identity; keep only the lead in roleDefinition
+	lead, examples := extractExamples(agent.Description)
+	roleDefinition := agent.RoleDefinition
+	if roleDefinition == "" && enrichment.RoleDefinition != "" {
+		roleDefinition = enrichment.RoleDefinition
+		traced(enricherTrace("roleDefinition", roleDefinition))
+	} else if roleDefinition == "" {
+		roleDefinition = lead
+		if agent.Format == "claude" {
+			// Claude descriptions are routing hints; Kilo wants an identity statement
+			var fallback string
+			roleDefinition, fallback = buildRoleDefinition(formattedName, lead, markdown)
+			if fallback != "" {
+				fallbacks = append(fallbacks, ConversionLoss{"roleDefinition", fallback})
+			}
+		}
 	}
 
-	agent, markdown, err := c.parseFrontmatter(string(content))
-	if err != nil {
-		return nil, err
+	// Generate whenToUse description based on agent characteristics
+	whenToUse := agent.WhenToUse
+	if whenToUse == "" {
+		var trace *DecisionTrace
+		if enrichment.WhenToUse != "" {
+			whenToUse, trace = enrichment.WhenToUse, enricherTrace("whenToUse", enrichment.WhenToUse)
+		} else {
+			whenToUse, trace = c.generateWhenToUse(agent.Description, analysis)
+		}
+		traced(trace)
 	}
 
-	slug := c.generateSlug(agent.Name)
-	groups := c.determineGroups(agent.Name, agent.Description, markdown)
-	fileRegex, fileDesc := c.determineFileRestrictions(agent.Name, agent.Description, markdown)
+	customInstructions := markdown
+	if identity := identityParagraph(markdown); identity != "" && identity == roleDefinition {
+		// The body's "You are …" paragraph became the roleDefinition; don't repeat it
+		customInstructions = withoutParagraph(markdown, identity)
+	}
+	var ruleFiles []RuleFile
+	if c.splitter != nil {
+		split := c.splitter.Split(markdown, roleDefinition)
+		roleDefinition = strings.Join(append([]string{roleDefinition}, split.Role...), "\n\n")
+		customInstructions = split.Instructions
+		ruleFiles = split.RuleFiles
+	}
 
-	// Generate icon and description
-	iconName := c.iconSelector.SelectIcon(agent.Name, agent.Description, markdown)
-	shortDescription := generateDescription(agent.Name, agent.Description, markdown)
-
-	// Format name properly (capitalize each word)
-	nameParts := strings.Split(agent.Name, "-")
-	for i, part := range nameParts {
-		if len(part) > 0 {
-			nameParts[i] = strings.ToUpper(part[:1]) + strings.ToLower(part[1:])
+	if len(examples) > 0 {
+		if c.examples == "instructions" {
+			customInstructions = strings.TrimSpace(customInstructions + "\n\n" + examplesSection(examples))
+		} else if triggers := examplesTriggers(examples); triggers != "" {
+			whenToUse += " " + triggers
 		}
 	}
-	formattedName := strings.Join(nameParts, " ")
 
-	// Generate whenToUse description based on agent characteristics
-	whenToUse := c.generateWhenToUse(agent.Name, agent.Description, markdown)
+	// Rule files diff far better than one large YAML scalar
+	if c.instructionsAsRules && customInstructions != "" {
+		ruleFiles = append([]RuleFile{{Name: "instructions.md", Content: customInstructions + "\n"}}, ruleFiles...)
+		customInstructions = ""
+	}
+
+	// The emoji is only added now so roleDefinition reads "You are an AI Engineer"
+	displayName := formattedName
+	if nameFormatter.emoji {
+		displayName = withEmoji(formattedName, iconName)
+	}
 
 	mode := &KiloMode{
 		Slug:               slug,
-		Name:               formattedName,
+		Name:               displayName,
 		IconName:           iconName,
-		RoleDefinition:     agent.Description,
+		RoleDefinition:     roleDefinition,
 		WhenToUse:          whenToUse,
 		Description:        shortDescription,
 		Groups:             groups,
-		CustomInstructions: markdown,
+		CustomInstructions: customInstructions,
 		Source:             "project", // Default to project, user can change on import
 		OriginalModel:      agent.Model,
+		Examples:           examples,
+		RuleFiles:          ruleFiles,
+		Traces:             traces,
+		IconCandidates:     iconCandidates,
 	}
 
-	if fileRegex != "" {
-		mode.FileRegex = fileRegex
-		// For file restrictions, override the generated description
-		mode.Description = fileDesc
+	if len(agent.Extras) > 0 {
+		mode.ClaudeExtras = agent.Extras
+		if c.claudeExtras == "inline" {
+			mode.XClaude = agent.Extras
+		}
 	}
 
-	return mode, nil
+	mode.FileRegex, mode.FileRegexDescription = fileRegex, fileDesc
+
+	mode.Losses = append(append(c.conversionLosses(agent, mode), fallbacks...), capped...)
+
+	return mode
 }
 
-// convertAgentWithStats converts a Claude Code sub-agent to Kilo Code mode and returns sanitization stats
-func (c *Converter) convertAgentWithStats(filePath string) (*KiloMode, bool, error) {
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, false, fmt.Errorf("error reading file %s: %w", filePath, err)
+// decodeFrontmatter unmarshals YAML frontmatter into out, retrying with the sanitizer on failure
+func (c *Converter) decodeFrontmatter(block *frontmatterBlock, out interface{}) (*SanitizationRecord, error) {
+	if block.Format == "toml" {
+		return nil, decodeTOMLFrontmatter(block, out)
 	}
 
-	agent, markdown, wasSanitized, err := c.parseFrontmatterWithStats(string(content))
-	if err != nil {
-		return nil, false, err
+	yamlContent := block.Content
+	err := yaml.Unmarshal([]byte(yamlContent), out)
+	if err == nil {
+		return nil, nil
 	}
 
-	slug := c.generateSlug(agent.Name)
-	groups := c.determineGroups(agent.Name, agent.Description, markdown)
-	fileRegex, fileDesc := c.determineFileRestrictions(agent.Name, agent.Description, markdown)
+	// Try sanitization
+	sanitizedYAML, strategies, sanitizeErr := c.yamlSanitizer.Repair(yamlContent)
+	if sanitizeErr != nil {
+		if c.enricher != nil {
+			if record, ok := c.enrichFrontmatter(block, out, err); ok {
+				return record, nil
+			}
+		}
+		// Report the original error; its position refers to the file as written
+		return nil, block.yamlError(err, "automatic sanitization could not repair it")
+	}
 
-	// Generate icon and description
-	iconName := c.iconSelector.SelectIcon(agent.Name, agent.Description, markdown)
-	shortDescription := generateDescription(agent.Name, agent.Description, markdown)
-
-	// Format name properly (capitalize each word)
-	nameParts := strings.Split(agent.Name, "-")
-	for i, part := range nameParts {
-		if len(part) > 0 {
-			nameParts[i] = strings.ToUpper(part[:1]) + strings.ToLower(part[1:])
+	// Retry with sanitized content
+	if yaml.Unmarshal([]byte(sanitizedYAML), out) != nil {
+		if c.enricher != nil {
+			if record, ok := c.enrichFrontmatter(block, out, err); ok {
+				return record, nil
+			}
 		}
+		// The retry's position refers to the sanitized text, whose lines no longer match the file
+		return nil, block.yamlError(err, "still invalid after automatic sanitization")
 	}
-	formattedName := strings.Join(nameParts, " ")
 
-	// Generate whenToUse description based on agent characteristics
-	whenToUse := c.generateWhenToUse(agent.Name, agent.Description, markdown)
+	// Log successful sanitization
+	fmt.Printf("  ⚠ Applied YAML sanitization (%s)\n", strings.Join(strategies, ", "))
+	return &SanitizationRecord{
+		Original:   yamlContent,
+		Sanitized:  sanitizedYAML,
+		Keys:       changedKeys(yamlContent, sanitizedYAML),
+		Strategies: strategies,
+		StartLine:  block.StartLine,
+	}, nil
+}
 
-	mode := &KiloMode{
-		Slug:               slug,
-		Name:               formattedName,
-		IconName:           iconName,
-		RoleDefinition:     agent.Description,
-		WhenToUse:          whenToUse,
-		Description:        shortDescription,
-		Groups:             groups,
-		CustomInstructions: markdown,
-		Source:             "project", // Default to project, user can change on import
-		OriginalModel:      agent.Model,
+// decodeTOMLFrontmatter decodes +++ frontmatter into out by way of YAML, so the same struct tags apply
+func decodeTOMLFrontmatter(block *frontmatterBlock, out interface{}) error {
+	values, err := parseTOML(block.Content)
+	if err != nil {
+		return block.tomlError(err)
 	}
-
-	if fileRegex != "" {
-		mode.FileRegex = fileRegex
-		// For file restrictions, override the generated description
-		mode.Description = fileDesc
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return block.newError(IssueInvalidTOML, block.StartLine, 0, err.Error())
 	}
-
-	return mode, wasSanitized, nil
+	if err := yaml.Unmarshal(data, out); err != nil {
+		return block.newError(IssueInvalidTOML, block.StartLine, 0, strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+	return nil
 }
 
 // parseFrontmatterWithStats extracts YAML frontmatter and markdown content with sanitization tracking
-func (c *Converter) parseFrontmatterWithStats(content string) (*ClaudeAgent, string, bool, error) {
-	// Normalize line endings
-	content = strings.ReplaceAll(content, "\r\n", "\n")
-	content = strings.ReplaceAll(content, "\r", "\n")
-	trimmed := strings.TrimSpace(content)
-
-	lines := strings.Split(trimmed, "\n")
-	if len(lines) < 3 || lines[0] != "---" {
-		return nil, "", false, fmt.Errorf("no valid YAML frontmatter found - first line: %q", lines[0])
-	}
-
-	// Find the closing ---
-	var yamlEnd int
-	for i := 1; i < len(lines); i++ {
-		if lines[i] == "---" {
-			yamlEnd = i
-			break
-		}
-	}
-
-	if yamlEnd == 0 {
-		return nil, "", false, fmt.Errorf("no closing --- found")
+func (c *Converter) parseFrontmatterWithStats(content string) (*ClaudeAgent, string, *SanitizationRecord, error) {
+	block, err := splitFrontmatter(content)
+	if err != nil {
+		return nil, "", nil, err
 	}
 
-	yamlContent := strings.Join(lines[1:yamlEnd], "\n")
-	markdownContent := strings.Join(lines[yamlEnd+1:], "\n")
-
 	var agent ClaudeAgent
-	wasSanitized := false
-	err := yaml.Unmarshal([]byte(yamlContent), &agent)
-
+	sanitization, err := c.decodeFrontmatter(block, &agent)
 	if err != nil {
-		// Try sanitization
-		sanitizedYAML, sanitizeErr := c.yamlSanitizer.SanitizeFrontmatter(yamlContent)
-		if sanitizeErr != nil {
-			return nil, "", false, fmt.Errorf("YAML parsing failed, sanitization also failed: original error: %w, sanitization error: %v", err, sanitizeErr)
-		}
-
-		// Retry with sanitized content
-		err = yaml.Unmarshal([]byte(sanitizedYAML), &agent)
-		if err != nil {
-			return nil, "", false, fmt.Errorf("YAML parsing failed even after sanitization: %w", err)
-		}
-
-		wasSanitized = true
-		// Log successful sanitization
-		fmt.Printf("  ⚠ Applied YAML sanitization\n")
+		return nil, "", nil, err
 	}
 
+	// Missing fields are reported at the opening delimiter
 	if agent.Name == "" {
-		return nil, "", wasSanitized, fmt.Errorf("missing required 'name' field")
+		return nil, "", sanitization, block.newError(IssueMissingName, block.StartLine-1, 0, "missing required 'name' field")
 	}
 	if agent.Description == "" {
-		return nil, "", wasSanitized, fmt.Errorf("missing required 'description' field")
+		return nil, "", sanitization, block.newError(IssueMissingDescription, block.StartLine-1, 0, "missing required 'description' field")
 	}
 
-	return &agent, strings.TrimSpace(markdownContent), wasSanitized, nil
+	return &agent, block.Body, sanitization, nil
 }
@@ -54,7 +54,7 @@ No desc.`
 
 func TestDetermineGroups(t *testing.T) {
 	c := NewConverter()
-	groups := c.determineGroups("AI Engineer", "", "")
+	groups, _ := c.determineGroups(newAnalysisText("AI Engineer", "", ""), nil)
 	if len(groups) == 0 {
 		t.Error("Expected non-empty groups")
 	}
@@ -62,7 +62,7 @@ func TestDetermineGroups(t *testing.T) {
 
 func TestDetermineFileRestrictions(t *testing.T) {
 	c := NewConverter()
-	re, desc := c.determineFileRestrictions("Architect Reviewer", "", "")
+	re, desc := c.determineFileRestrictions(newAnalysisText("Architect Reviewer", "", ""))
 	if re != "\\.md$" || desc != "Markdown files only" {
 		t.Errorf("Expected markdown restriction, got %q, %q", re, desc)
 	}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,20 +12,117 @@ import (
 
 // DiagnosticReport generates detailed reports about conversion issues
 type DiagnosticReport struct {
-	TotalFiles      int
-	SuccessfulFiles int
-	FailedFiles     int
-	SanitizedFiles  int
-	Issues          []FileIssue
-	Timestamp       time.Time
+	TotalFiles      int               `json:"totalFiles"`
+	SuccessfulFiles int               `json:"successfulFiles"`
+	FailedFiles     int               `json:"failedFiles"`
+	SanitizedFiles  int               `json:"sanitizedFiles"`
+	Sanitized       []SanitizedFile   `json:"sanitized,omitempty"`
+	Issues          []FileIssue       `json:"issues,omitempty"`
+	Plugins         []PluginSummary   `json:"plugins,omitempty"`
+	Explanations    []ModeExplanation `json:"explanations,omitempty"`   // JSON report only; too long for the markdown
+	IconDuplicates  []IconDuplicate   `json:"iconDuplicates,omitempty"` // Icons still shared after -unique-icons
+	Timestamp       time.Time         `json:"timestamp"`
 }
 
 // FileIssue represents a specific issue with a file
 type FileIssue struct {
-	FilePath    string
-	IssueType   string
-	Description string
-	Suggestion  string
+	FilePath    string    `json:"file"`
+	IssueType   IssueType `json:"type"`
+	Description string    `json:"description"`
+	Suggestion  string    `json:"suggestion,omitempty"`
+	Plugin      string    `json:"plugin,omitempty"`
+	Line        int       `json:"line,omitempty"`
+	Column      int       `json:"column,omitempty"`
+	Snippet     string    `json:"snippet,omitempty"`
+}
+
+// SanitizedFile records which repair strategies fixed a file's frontmatter
+type SanitizedFile struct {
+	FilePath   string   `json:"file"`
+	Strategies []string `json:"strategies"`
+	Keys       []string `json:"keys,omitempty"`
+	Diff       string   `json:"diff,omitempty"` // Unified diff from the original to the sanitized frontmatter
+}
+
+// issueSuggestions gives the default fix for each kind of failure
+var issueSuggestions = map[IssueType]string{
+	IssueConversionError:         "Check YAML frontmatter syntax and required fields",
+	IssueMissingFrontmatter:      "Start the file with a --- line (or +++ for TOML), followed by the frontmatter and a matching closing line",
+	IssueMissingClosingDelimiter: "Add a closing line matching the opening delimiter after the last frontmatter key",
+	IssueInvalidYAML:             "Fix the YAML at the marked position; quote values containing ': ' or use a literal block (|)",
+	IssueInvalidTOML:             "Fix the TOML at the marked position; strings must be quoted and arrays bracketed",
+	IssueMissingName:             "Add a name field to the YAML frontmatter",
+	IssueMissingDescription:      "Add a description field to the YAML frontmatter",
+	IssueStrictMode:              "Fix the source agent so every field converts without loss or heuristic fallback",
+	IssuePolicyViolation:         "Change the agent to satisfy the rule, or add an exception to the rule's unless block in the policy file",
+	IssueDuplicateSlug:           "Rename one of the agents, or set a slug strategy or plugin namespace that tells them apart",
+	IssueUnknownIcon:             "Check the name at https://microsoft.github.io/vscode-codicons/ or refresh the list with `icons update --from codicon.csv`",
+}
+
+// newFileIssue classifies a conversion error for the diagnostic report
+func newFileIssue(filePath string, err error) FileIssue {
+	issue := FileIssue{
+		FilePath:    filePath,
+		IssueType:   IssueConversionError,
+		Description: err.Error(),
+	}
+
+	var fmErr *FrontmatterError
+	var strictErr *StrictModeError
+	var policyErr *PolicyError
+	switch {
+	case errors.As(err, &fmErr):
+		issue.IssueType = fmErr.Kind
+		issue.Description = fmErr.Message
+		issue.Line = fmErr.Line
+		issue.Column = fmErr.Column
+		issue.Snippet = fmErr.Snippet
+	case errors.As(err, &strictErr):
+		issue.IssueType = IssueStrictMode
+	case errors.As(err, &policyErr):
+		issue.IssueType = IssuePolicyViolation
+	}
+
+	issue.Suggestion = issueSuggestions[issue.IssueType]
+	return issue
+}
+
+// errorSnippet returns the annotated source snippet carried by an error, if any
+func errorSnippet(err error) string {
+	var fmErr *FrontmatterError
+	if errors.As(err, &fmErr) {
+		return fmErr.Snippet
+	}
+	return ""
+}
+
+// PluginSummary records conversion results for one Claude Code plugin
+type PluginSummary struct {
+	Name        string   `json:"name"`
+	Marketplace string   `json:"marketplace,omitempty"`
+	Root        string   `json:"root"`
+	Files       int      `json:"files"`
+	Converted   int      `json:"converted"`
+	Modes       []string `json:"modes,omitempty"`
+	Failed      []string `json:"failed,omitempty"`
+	Commands    int      `json:"commands,omitempty"`
+	HasHooks    bool     `json:"hasHooks,omitempty"`
+	HasMCP      bool     `json:"hasMCP,omitempty"`
+}
+
+// skippedComponents lists bundled plugin parts that have no Kilo mode equivalent
+func (p PluginSummary) skippedComponents() string {
+	var parts []string
+	if p.Commands > 0 {
+		parts = append(parts, fmt.Sprintf("%d commands", p.Commands))
+	}
+	if p.HasHooks {
+		parts = append(parts, "hooks")
+	}
+	if p.HasMCP {
+		parts = append(parts, "MCP servers")
+	}
+	return strings.Join(parts, ", ")
 }
 
 // GenerateDiagnosticReport creates a comprehensive report of conversion results
@@ -37,6 +136,12 @@ func GenerateDiagnosticReport(inputDir string, issues []FileIssue, totalFiles, s
 		Timestamp:       time.Now(),
 	}
 
+	return SaveDiagnosticReport(inputDir, report)
+}
+
+// SaveDiagnosticReport writes a prepared report to the input directory, as markdown
+// for people and as JSON for tools
+func SaveDiagnosticReport(inputDir string, report DiagnosticReport) error {
 	// Generate report content
 	content := generateReportContent(report)
 
@@ -46,6 +151,15 @@ func GenerateDiagnosticReport(inputDir string, issues []FileIssue, totalFiles, s
 		return fmt.Errorf("failed to write diagnostic report: %w", err)
 	}
 
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode diagnostic report: %w", err)
+	}
+	jsonPath := filepath.Join(inputDir, "conversion-diagnostic-report.json")
+	if err := os.WriteFile(jsonPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write diagnostic report: %w", err)
+	}
+
 	fmt.Printf("\n📊 Diagnostic report saved to: %s\n", reportPath)
 	return nil
 }
@@ -78,12 +192,50 @@ func generateReportContent(report DiagnosticReport) string {
 		content.WriteString("❌ **Multiple files need attention** - consider reviewing the patterns below.\n\n")
 	}
 
+	// Plugins section
+	if len(report.Plugins) > 0 {
+		content.WriteString("## Plugins\n\n")
+		content.WriteString("| Plugin | Marketplace | Path | Agents | Converted | Not Converted |\n")
+		content.WriteString("|--------|-------------|------|--------|-----------|---------------|\n")
+		for _, plugin := range report.Plugins {
+			content.WriteString(fmt.Sprintf("| %s | %s | `%s` | %d | %d | %s |\n",
+				plugin.Name, plugin.Marketplace, plugin.Root, plugin.Files, plugin.Converted, plugin.skippedComponents()))
+		}
+		content.WriteString("\n")
+
+		for _, plugin := range report.Plugins {
+			content.WriteString(fmt.Sprintf("### %s\n\n", plugin.Name))
+			if len(plugin.Modes) > 0 {
+				content.WriteString(fmt.Sprintf("**Modes**: `%s`\n", strings.Join(plugin.Modes, "`, `")))
+			}
+			if len(plugin.Failed) > 0 {
+				content.WriteString(fmt.Sprintf("**Failed**: `%s`\n", strings.Join(plugin.Failed, "`, `")))
+			}
+			if len(plugin.Modes) == 0 && len(plugin.Failed) == 0 {
+				content.WriteString("No agents found.\n")
+			}
+			content.WriteString("\n")
+		}
+	}
+
+	// Icons -unique-icons could not separate
+	if len(report.IconDuplicates) > 0 {
+		content.WriteString("## Duplicate Icons\n\n")
+		content.WriteString("These modes ran out of unused candidate icons with `-unique-icons`. Set `iconName` in the source or add an icon rule (`-icon-rules`) to tell them apart.\n\n")
+		content.WriteString("| Icon | Modes |\n")
+		content.WriteString("|------|-------|\n")
+		for _, duplicate := range report.IconDuplicates {
+			content.WriteString(fmt.Sprintf("| `%s` | %s |\n", duplicate.Icon, strings.Join(duplicate.Modes, ", ")))
+		}
+		content.WriteString("\n")
+	}
+
 	// Issues section
 	if len(report.Issues) > 0 {
 		content.WriteString("## Issues Found\n\n")
 
 		// Group issues by type
-		issueGroups := make(map[string][]FileIssue)
+		issueGroups := make(map[IssueType][]FileIssue)
 		for _, issue := range report.Issues {
 			issueGroups[issue.IssueType] = append(issueGroups[issue.IssueType], issue)
 		}
@@ -92,11 +244,25 @@ func generateReportContent(report DiagnosticReport) string {
 			content.WriteString(fmt.Sprintf("### %s (%d files)\n\n", issueType, len(issues)))
 
 			for _, issue := range issues {
-				content.WriteString(fmt.Sprintf("**File**: `%s`\n", issue.FilePath))
+				if issue.Line > 0 {
+					location := fmt.Sprintf("%s:%d", issue.FilePath, issue.Line)
+					if issue.Column > 0 {
+						location += fmt.Sprintf(":%d", issue.Column)
+					}
+					content.WriteString(fmt.Sprintf("**File**: `%s`\n", location))
+				} else {
+					content.WriteString(fmt.Sprintf("**File**: `%s`\n", issue.FilePath))
+				}
+				if issue.Plugin != "" {
+					content.WriteString(fmt.Sprintf("**Plugin**: %s\n", issue.Plugin))
+				}
 				content.WriteString(fmt.Sprintf("**Issue**: %s\n", issue.Description))
 				if issue.Suggestion != "" {
 					content.WriteString(fmt.Sprintf("**Suggestion**: %s\n", issue.Suggestion))
 				}
+				if issue.Snippet != "" {
+					content.WriteString("\n```\n" + issue.Snippet + "```\n")
+				}
 				content.WriteString("\n")
 			}
 		}
@@ -107,10 +273,14 @@ func generateReportContent(report DiagnosticReport) string {
 
 	if report.SanitizedFiles > 0 {
 		content.WriteString(fmt.Sprintf("### YAML Sanitization Applied (%d files)\n\n", report.SanitizedFiles))
-		content.WriteString("The converter automatically fixed common YAML issues in these files:\n")
-		content.WriteString("- Long descriptions with unescaped quotes → Converted to YAML literal blocks\n")
-		content.WriteString("- Tools field as comma-separated string → Converted to YAML array format\n")
-		content.WriteString("- Embedded examples and special characters → Properly formatted\n\n")
+		if len(report.Sanitized) > 0 {
+			writeSanitizedFiles(&content, report.Sanitized)
+		} else {
+			content.WriteString("The converter automatically fixed common YAML issues in these files:\n")
+			content.WriteString("- Long descriptions with unescaped quotes → Converted to YAML literal blocks\n")
+			content.WriteString("- Tools field as comma-separated string → Converted to YAML array format\n")
+			content.WriteString("- Embedded examples and special characters → Properly formatted\n\n")
+		}
 		content.WriteString("✅ **No action needed** - these files were automatically fixed during conversion.\n\n")
 	}
 
@@ -124,13 +294,10 @@ func generateReportContent(report DiagnosticReport) string {
 		content.WriteString("#### Option 2: LLM-Assisted Fix\n")
 		content.WriteString("Use this prompt with an LLM to fix the files:\n\n")
 		content.WriteString("```\n")
-		content.WriteString("Please fix the YAML frontmatter in this Claude agent file. The issues are:\n")
-		content.WriteString("1. Ensure proper YAML syntax with correct quoting\n")
-		content.WriteString("2. Convert long descriptions to YAML literal blocks (|) if they contain quotes or examples\n")
-		content.WriteString("3. Convert tools field from comma-separated string to YAML array format\n")
-		content.WriteString("4. Preserve all content meaning while making it valid YAML\n\n")
+		content.WriteString(frontmatterFixInstructions + "\n")
 		content.WriteString("[Paste the problematic file content here]\n")
 		content.WriteString("```\n\n")
+		content.WriteString("Or rerun with `-enrich <endpoint>` to have a local OpenAI-compatible model (llama.cpp, Ollama) apply the same fix automatically.\n\n")
 	}
 
 	// Best practices section
@@ -168,3 +335,35 @@ func generateReportContent(report DiagnosticReport) string {
 
 	return content.String()
 }
+
+// writeSanitizedFiles lists each repaired file with the strategies that fired and what they do
+func writeSanitizedFiles(content *strings.Builder, files []SanitizedFile) {
+	content.WriteString("The converter automatically repaired these files. Review each diff and fix the source file if you would rather not rely on the rewrite:\n\n")
+	fired := make(map[string]bool)
+	for _, file := range files {
+		content.WriteString(fmt.Sprintf("- `%s`: %s", file.FilePath, strings.Join(file.Strategies, ", ")))
+		if len(file.Keys) > 0 {
+			content.WriteString(fmt.Sprintf(" (rewrote %s)", strings.Join(file.Keys, ", ")))
+		}
+		content.WriteString("\n")
+		if file.Diff != "" {
+			content.WriteString("\n  ```diff\n")
+			content.WriteString(indentLines(strings.TrimSuffix(file.Diff, "\n"), "  "))
+			content.WriteString("  ```\n")
+		}
+		for _, name := range file.Strategies {
+			fired[name] = true
+		}
+	}
+
+	content.WriteString("\n| Strategy | Fix |\n|----------|-----|\n")
+	for _, strategy := range NewYAMLSanitizer().strategies {
+		if fired[strategy.Name] {
+			content.WriteString(fmt.Sprintf("| `%s` | %s |\n", strategy.Name, strategy.Description))
+		}
+	}
+	if fired[enricherRepairStrategy] {
+		content.WriteString(fmt.Sprintf("| `%s` | Rewritten by the `-enrich` model; check the diff closely |\n", enricherRepairStrategy))
+	}
+	content.WriteString("\n")
+}
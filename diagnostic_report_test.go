@@ -44,3 +44,50 @@ func TestGenerateReportContent_ContainsMetrics(t *testing.T) {
 		t.Error("Expected issue type in report content")
 	}
 }
+
+func TestNewFileIssue_Classifies(t *testing.T) {
+	fmErr := &FrontmatterError{Kind: IssueInvalidYAML, Line: 3, Column: 5, Message: "bad", Snippet: "3 | x\n"}
+	issue := newFileIssue("a.md", fmErr)
+	if issue.IssueType != IssueInvalidYAML || issue.Line != 3 || issue.Snippet == "" || issue.Suggestion == "" {
+		t.Errorf("Unexpected issue: %+v", issue)
+	}
+
+	content := generateReportContent(DiagnosticReport{TotalFiles: 1, FailedFiles: 1, Issues: []FileIssue{issue}})
+	if !strings.Contains(content, "`a.md:3:5`") || !strings.Contains(content, "3 | x") {
+		t.Error("Expected location and snippet in report content")
+	}
+
+	issue = newFileIssue("b.md", &StrictModeError{Slug: "b"})
+	if issue.IssueType != IssueStrictMode {
+		t.Errorf("Expected strict mode issue, got %s", issue.IssueType)
+	}
+}
+
+func TestGenerateReportContent_ListsRepairStrategies(t *testing.T) {
+	report := DiagnosticReport{
+		TotalFiles:      1,
+		SuccessfulFiles: 1,
+		SanitizedFiles:  1,
+		Sanitized:       []SanitizedFile{{FilePath: "a.md", Strategies: []string{"unquoted-colon"}, Keys: []string{"description"}}},
+	}
+	content := generateReportContent(report)
+	if !strings.Contains(content, "- `a.md`: unquoted-colon (rewrote description)") {
+		t.Error("Expected per-file strategies in report content")
+	}
+	if !strings.Contains(content, "| `unquoted-colon` |") || strings.Contains(content, "| `tab-indentation` |") {
+		t.Error("Expected only fired strategies in the legend")
+	}
+}
+
+func TestGenerateReportContent_EmbedsSanitizationDiff(t *testing.T) {
+	record := &SanitizationRecord{Original: "name: a\ntools: Read, Grep", Sanitized: "name: a\ntools: [Read, Grep]", StartLine: 2}
+	report := DiagnosticReport{
+		TotalFiles:     1,
+		SanitizedFiles: 1,
+		Sanitized:      []SanitizedFile{{FilePath: "a.md", Strategies: []string{"tools-string"}, Diff: record.Diff("a.md")}},
+	}
+	content := generateReportContent(report)
+	if !strings.Contains(content, "  ```diff\n  --- a.md (original)\n") || !strings.Contains(content, "  @@ -2,2 +2,2 @@\n") || !strings.Contains(content, "  +tools: [Read, Grep]\n") {
+		t.Errorf("Expected embedded diff in report content:\n%s", content)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// diffOp is one line of an edit script: ' ' kept, '-' removed, '+' added
+type diffOp struct {
+	kind rune
+	text string
+	a, b int // 0-based line indexes in the old and new text
+}
+
+// diffLines computes a line edit script from a to b using the longest common subsequence
+func diffLines(a, b []string) []diffOp {
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i], i, j})
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] > lcs[i+1][j]):
+			ops = append(ops, diffOp{'+', b[j], i, j})
+			j++
+		default:
+			ops = append(ops, diffOp{'-', a[i], i, j})
+			i++
+		}
+	}
+	return ops
+}
+
+// unifiedDiff renders the changes from a to b as a unified diff. Line numbers
+// in hunk headers are shifted by offset so they refer to the enclosing file.
+func unifiedDiff(a, b, fromLabel, toLabel string, offset int) string {
+	ops := diffLines(strings.Split(a, "\n"), strings.Split(b, "\n"))
+
+	var diff strings.Builder
+	for start := 0; start < len(ops); {
+		// Find the next change
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// Extend the hunk while changes are close enough to share context
+		end := start
+		for k := start; k < len(ops); k++ {
+			if ops[k].kind != ' ' {
+				end = k
+			} else if k-end > 2*diffContext {
+				break
+			}
+		}
+		first := max(0, start-diffContext)
+		last := min(len(ops)-1, end+diffContext)
+
+		if diff.Len() == 0 {
+			diff.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", fromLabel, toLabel))
+		}
+		writeHunk(&diff, ops[first:last+1], offset)
+		start = last + 1
+	}
+	return diff.String()
+}
+
+// writeHunk writes one @@ hunk for a contiguous run of edit operations
+func writeHunk(diff *strings.Builder, ops []diffOp, offset int) {
+	var oldCount, newCount int
+	for _, op := range ops {
+		if op.kind != '+' {
+			oldCount++
+		}
+		if op.kind != '-' {
+			newCount++
+		}
+	}
+
+	oldStart, newStart := ops[0].a+1+offset, ops[0].b+1+offset
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+
+	diff.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount))
+	for _, op := range ops {
+		diff.WriteString(string(op.kind) + op.text + "\n")
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "name: a\ndescription: Use when: x\ntools: Read, Grep\nmodel: sonnet"
+	b := "name: a\ndescription: \"Use when: x\"\ntools: [Read, Grep]\nmodel: sonnet"
+	got := unifiedDiff(a, b, "a.md (original)", "a.md (sanitized)", 1)
+	want := `--- a.md (original)
++++ a.md (sanitized)
+@@ -2,4 +2,4 @@
+ name: a
+-description: Use when: x
+-tools: Read, Grep
++description: "Use when: x"
++tools: [Read, Grep]
+ model: sonnet
+`
+	if got != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestUnifiedDiff_SeparateHunks(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12"
+	b := "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13"
+	got := unifiedDiff(a, b, "a", "b", 0)
+	want := `--- a
++++ b
+@@ -1,4 +1,4 @@
+-1
++one
+ 2
+ 3
+ 4
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+`
+	if got != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, got)
+	}
+
+	if diff := unifiedDiff("same", "same", "a", "b", 0); diff != "" {
+		t.Errorf("Expected no diff for identical text, got %q", diff)
+	}
+}
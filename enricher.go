@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// enricherRepairStrategy names frontmatter repairs made by an Enricher in sanitization records
+const enricherRepairStrategy = "llm-repair"
+
+// enrichAPIKeyEnv names the environment variable read when -enrich-api-key is not given
+const enrichAPIKeyEnv = "CLAUDE2KILO_ENRICH_API_KEY"
+
+// maxEnrichedBodyChars caps how much of a prompt body is sent for enrichment
+const maxEnrichedBodyChars = 8000
+
+// frontmatterFixInstructions is the brief for repairing frontmatter, shared by the
+// report's manual LLM prompt and the enricher
+const frontmatterFixInstructions = `Please fix the YAML frontmatter in this Claude agent file. The issues are:
+1. Ensure proper YAML syntax with correct quoting
+2. Convert long descriptions to YAML literal blocks (|) if they contain quotes or examples
+3. Convert tools field from comma-separated string to YAML array format
+4. Preserve all content meaning while making it valid YAML
+`
+
+// describePrompt asks for the descriptive mode fields as JSON
+const describePrompt = `You convert Claude Code sub-agents into Kilo Code modes. Given an agent's name, description and prompt, reply with only a JSON object with these string keys:
+- roleDefinition: a second-person identity statement ("You are ..."), one to three sentences
+- whenToUse: one or two sentences starting "Use this mode when" that tell an orchestrator when to pick this mode
+- description: a 2-5 word summary for the mode picker`
+
+// Enricher improves what the built-in heuristics handle poorly, typically with a language model
+type Enricher interface {
+	// RepairFrontmatter returns corrected YAML for frontmatter that failed to parse
+	RepairFrontmatter(frontmatter string, parseErr error) (string, error)
+	// Describe writes roleDefinition, whenToUse and description for an agent
+	Describe(agent *SourceAgent) (*Enrichment, error)
+}
+
+// Enrichment holds mode fields written by an Enricher; empty fields keep the heuristic value
+type Enrichment struct {
+	RoleDefinition string `json:"roleDefinition"`
+	WhenToUse      string `json:"whenToUse"`
+	Description    string `json:"description"`
+}
+
+// OpenAIEnricher calls an OpenAI-compatible chat completions endpoint, such as a local
+// llama.cpp server or Ollama, and caches every answer by a hash of its request
+type OpenAIEnricher struct {
+	Endpoint string // Base URL, e.g. http://localhost:11434/v1
+	Model    string
+	APIKey   string // Optional; local servers usually need none
+	CacheDir string // Empty disables the cache
+	Client   *http.Client
+}
+
+// chatMessage is one message of a chat completions request or response
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+var codeFenceRe = regexp.MustCompile("(?s)^\\s*```[a-zA-Z]*\\n(.*?)\\n?```\\s*$")
+
+// NewOpenAIEnricher creates an enricher for endpoint, caching answers in cacheDir. The
+// apiKey is sent only to this endpoint, so it is never picked up from another tool's settings.
+func NewOpenAIEnricher(endpoint, model, apiKey, cacheDir string) *OpenAIEnricher {
+	return &OpenAIEnricher{
+		Endpoint: strings.TrimSuffix(endpoint, "/"),
+		Model:    model,
+		APIKey:   apiKey,
+		CacheDir: cacheDir,
+		Client:   &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+// defaultEnrichCacheDir returns the per-user cache directory for enrichment answers
+func defaultEnrichCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "claude2kilo", "enrich")
+}
+
+// RepairFrontmatter asks the model to rewrite broken frontmatter as valid YAML
+func (e *OpenAIEnricher) RepairFrontmatter(frontmatter string, parseErr error) (string, error) {
+	system := frontmatterFixInstructions + "\nReply with only the corrected YAML, without --- delimiters or commentary."
+	user := fmt.Sprintf("Parser error: %v\n\n%s", parseErr, frontmatter)
+	answer, keep, err := e.complete(system, user)
+	if err != nil {
+		return "", err
+	}
+
+	repaired := strings.TrimSpace(strings.Trim(stripCodeFence(answer), "-\n"))
+	var values map[string]interface{}
+	if err := yaml.Unmarshal([]byte(repaired), &values); err != nil {
+		return "", fmt.Errorf("enricher answer is not valid YAML: %w", err)
+	}
+	keep()
+	return repaired, nil
+}
+
+// Describe asks the model for the descriptive mode fields
+func (e *OpenAIEnricher) Describe(agent *SourceAgent) (*Enrichment, error) {
+	user := fmt.Sprintf("Name: %s\nDescription: %s\n\nPrompt:\n%s", agent.Name, agent.Description, truncateUTF8(agent.Body, maxEnrichedBodyChars))
+	answer, keep, err := e.complete(describePrompt, user)
+	if err != nil {
+		return nil, err
+	}
+
+	var enrichment Enrichment
+	if err := json.Unmarshal([]byte(stripCodeFence(answer)), &enrichment); err != nil {
+		return nil, fmt.Errorf("enricher answer is not the expected JSON: %w", err)
+	}
+	keep()
+	return &enrichment, nil
+}
+
+// truncateUTF8 shortens text to at most limit bytes without splitting a character
+func truncateUTF8(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	for limit > 0 && !utf8.RuneStart(text[limit]) {
+		limit--
+	}
+	return text[:limit]
+}
+
+// complete sends one chat completion request, answering from the cache when it can.
+// Callers call keep once the answer proved usable, so a malformed answer is asked again next run.
+func (e *OpenAIEnricher) complete(system, user string) (answer string, keep func(), err error) {
+	request := map[string]interface{}{
+		"model":       e.Model,
+		"temperature": 0,
+		"messages":    []chatMessage{{Role: "system", Content: system}, {Role: "user", Content: user}},
+	}
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// The request fully determines the answer at temperature 0, so its hash is the cache key
+	sum := sha256.Sum256(append([]byte(e.Endpoint+"\n"), payload...))
+	key := hex.EncodeToString(sum[:])
+	if answer, ok := e.cached(key); ok {
+		return answer, func() {}, nil
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.Endpoint+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return "", nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.APIKey)
+	}
+
+	resp, err := e.Client.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("enricher request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, fmt.Errorf("enricher response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("enricher returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	var response struct {
+		Choices []struct {
+			Message chatMessage `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return "", nil, fmt.Errorf("enricher response: %w", err)
+	}
+	if len(response.Choices) == 0 {
+		return "", nil, fmt.Errorf("enricher response has no choices")
+	}
+
+	answer = response.Choices[0].Message.Content
+	return answer, func() { e.store(key, answer) }, nil
+}
+
+// cached returns a stored answer for a request hash
+func (e *OpenAIEnricher) cached(key string) (string, bool) {
+	if e.CacheDir == "" {
+		return "", false
+	}
+	data, err := os.ReadFile(filepath.Join(e.CacheDir, key+".txt"))
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// store saves an answer under its request hash; a failed write only costs a later request
+func (e *OpenAIEnricher) store(key, answer string) {
+	if e.CacheDir == "" {
+		return
+	}
+	if err := os.MkdirAll(e.CacheDir, 0755); err == nil {
+		os.WriteFile(filepath.Join(e.CacheDir, key+".txt"), []byte(answer), 0644)
+	}
+}
+
+// stripCodeFence removes a markdown code fence wrapped around a model's answer
+func stripCodeFence(answer string) string {
+	if match := codeFenceRe.FindStringSubmatch(answer); match != nil {
+		return match[1]
+	}
+	return strings.TrimSpace(answer)
+}
+
+// enrichFrontmatter lets the enricher repair frontmatter that sanitization could not
+func (c *Converter) enrichFrontmatter(block *frontmatterBlock, out interface{}, parseErr error) (*SanitizationRecord, bool) {
+	repaired, err := c.enricher.RepairFrontmatter(block.Content, parseErr)
+	if err != nil {
+		fmt.Printf("  ⚠ Enricher could not repair frontmatter: %v\n", err)
+		return nil, false
+	}
+	if err := yaml.Unmarshal([]byte(repaired), out); err != nil {
+		fmt.Printf("  ⚠ Enricher's frontmatter is still invalid: %v\n", err)
+		return nil, false
+	}
+
+	fmt.Printf("  ⚠ Applied YAML sanitization (%s)\n", enricherRepairStrategy)
+	return &SanitizationRecord{
+		Original:   block.Content,
+		Sanitized:  repaired,
+		Keys:       changedKeys(block.Content, repaired),
+		Strategies: []string{enricherRepairStrategy},
+		StartLine:  block.StartLine,
+	}, true
+}
+
+// enrich asks the enricher for an agent's descriptive fields. Failures are reported and
+// yield an empty Enrichment, so the heuristics decide as usual.
+func (c *Converter) enrich(agent *SourceAgent) *Enrichment {
+	// Source fields always win, so an agent that supplies all three needs no request
+	if c.enricher == nil || (agent.RoleDefinition != "" && agent.WhenToUse != "" && agent.Summary != "") {
+		return &Enrichment{}
+	}
+	enrichment, err := c.enricher.Describe(agent)
+	if err != nil {
+		fmt.Printf("  ⚠ Enricher skipped %s: %v\n", agent.Name, err)
+		return &Enrichment{}
+	}
+	enrichment.RoleDefinition = strings.TrimSpace(enrichment.RoleDefinition)
+	enrichment.WhenToUse = strings.TrimSpace(enrichment.WhenToUse)
+	enrichment.Description = strings.TrimSpace(enrichment.Description)
+	return enrichment
+}
+
+// enricherTrace records that a field was written by the enricher
+func enricherTrace(field, value string) *DecisionTrace {
+	return &DecisionTrace{Field: field, Value: value, Rule: "written by the enricher"}
+}
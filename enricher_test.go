@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"unicode/utf8"
+)
+
+// fakeCompletions serves /chat/completions, answering frontmatter repairs with repair
+// and every other request with describe. It counts the requests it receives.
+func fakeCompletions(t *testing.T, repair, describe string) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Path != "/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		var request struct {
+			Messages []chatMessage `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("Invalid request: %v", err)
+		}
+		answer := describe
+		if strings.HasPrefix(request.Messages[0].Content, frontmatterFixInstructions) {
+			answer = repair
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": chatMessage{Role: "assistant", Content: answer}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestOpenAIEnricher_DescribeIsCached(t *testing.T) {
+	server, requests := fakeCompletions(t, "", "```json\n"+`{"roleDefinition": "You are a Go reviewer.", "whenToUse": "Use this mode when reviewing Go code.", "description": "Go code review"}`+"\n```")
+	enricher := NewOpenAIEnricher(server.URL+"/", "test-model", "", t.TempDir())
+	agent := &SourceAgent{Name: "go-reviewer", Description: "Reviews Go code", Body: "Review carefully."}
+
+	for i := 0; i < 2; i++ {
+		enrichment, err := enricher.Describe(agent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if enrichment.Description != "Go code review" || enrichment.RoleDefinition != "You are a Go reviewer." {
+			t.Errorf("Unexpected enrichment: %+v", enrichment)
+		}
+	}
+	if *requests != 1 {
+		t.Errorf("Expected the second call to be answered from the cache, got %d requests", *requests)
+	}
+
+	agent.Body = "Review very carefully."
+	enricher.Describe(agent)
+	if *requests != 2 {
+		t.Errorf("Expected changed content to miss the cache, got %d requests", *requests)
+	}
+}
+
+func TestOpenAIEnricher_SendsOnlyItsOwnKey(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "sk-not-for-this-endpoint")
+	var authorization []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = append(authorization, r.Header.Get("Authorization"))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": chatMessage{Role: "assistant", Content: "{}"}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	agent := &SourceAgent{Name: "go-reviewer", Body: "Review carefully."}
+	for _, key := range []string{"", "local-key"} {
+		if _, err := NewOpenAIEnricher(server.URL, "test-model", key, "").Describe(agent); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(authorization) != 2 || authorization[0] != "" || authorization[1] != "Bearer local-key" {
+		t.Errorf("Expected no token, then the given one; got %q", authorization)
+	}
+}
+
+func TestOpenAIEnricher_MalformedAnswerIsNotCached(t *testing.T) {
+	answers := []string{`{"description": "Go code`, `{"description": "Go code review"}`}
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		answer := answers[min(requests, len(answers)-1)]
+		requests++
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": chatMessage{Role: "assistant", Content: answer}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	cache := t.TempDir()
+	agent := &SourceAgent{Name: "go-reviewer", Body: "Review carefully."}
+	if _, err := NewOpenAIEnricher(server.URL, "test-model", "", cache).Describe(agent); err == nil {
+		t.Fatal("Expected the truncated JSON to be rejected")
+	}
+	enrichment, err := NewOpenAIEnricher(server.URL, "test-model", "", cache).Describe(agent)
+	if err != nil || enrichment.Description != "Go code review" || requests != 2 {
+		t.Errorf("Expected the second run to ask the server again, got %+v, %v after %d requests", enrichment, err, requests)
+	}
+	if _, err := NewOpenAIEnricher(server.URL, "test-model", "", cache).Describe(agent); err != nil || requests != 2 {
+		t.Errorf("Expected the valid answer to be cached, got %v after %d requests", err, requests)
+	}
+}
+
+func TestTruncateUTF8(t *testing.T) {
+	body := strings.Repeat("a", maxEnrichedBodyChars-1) + "é and more"
+	got := truncateUTF8(body, maxEnrichedBodyChars)
+	if !utf8.ValidString(got) || got != strings.Repeat("a", maxEnrichedBodyChars-1) {
+		t.Errorf("Expected the cut before the split character, got %d bytes ending in %q", len(got), got[len(got)-3:])
+	}
+	if got := truncateUTF8("short", maxEnrichedBodyChars); got != "short" {
+		t.Errorf("Expected short text unchanged, got %q", got)
+	}
+}
+
+func TestBuildMode_EnricherFields(t *testing.T) {
+	server, _ := fakeCompletions(t, "", `{"roleDefinition": "You are a Go reviewer.", "whenToUse": "Use this mode when reviewing Go code.", "description": "Go code review"}`)
+	c := NewConverter()
+	c.enricher = NewOpenAIEnricher(server.URL, "test-model", "", "")
+
+	mode := c.buildMode(&SourceAgent{Name: "go-reviewer", Description: "Reviews Go code", Body: "Review carefully."})
+	if mode.RoleDefinition != "You are a Go reviewer." || mode.WhenToUse != "Use this mode when reviewing Go code." || mode.Description != "Go code review" {
+		t.Errorf("Expected the enricher's fields, got %q / %q / %q", mode.RoleDefinition, mode.WhenToUse, mode.Description)
+	}
+	for _, trace := range mode.Traces {
+		if trace.Field == "description" && trace.Rule != "written by the enricher" {
+			t.Errorf("Expected an enricher trace for description, got %q", trace.Rule)
+		}
+	}
+}
+
+func TestBuildMode_EnricherSkippedForCompleteModes(t *testing.T) {
+	server, requests := fakeCompletions(t, "", `{"description": "Unused"}`)
+	c := NewConverter()
+	c.enricher = NewOpenAIEnricher(server.URL, "test-model", "", "")
+
+	c.buildMode(&SourceAgent{Name: "docs-writer", Format: "roo", RoleDefinition: "You write docs.", WhenToUse: "Use for docs.", Summary: "Docs"})
+	if *requests != 0 {
+		t.Errorf("Expected no enricher request for a mode that supplies every field, got %d", *requests)
+	}
+	c.buildMode(&SourceAgent{Name: "docs-writer", Format: "roo", RoleDefinition: "You write docs.", Summary: "Docs"})
+	if *requests != 1 {
+		t.Errorf("Expected a request for the missing whenToUse, got %d", *requests)
+	}
+}
+
+func TestBuildMode_EnricherFailureKeepsHeuristics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	agent := &SourceAgent{Name: "api-designer", Description: "Designs REST APIs for backend services.", Body: "You design APIs."}
+	want := NewConverter().buildMode(agent)
+
+	c := NewConverter()
+	c.enricher = NewOpenAIEnricher(server.URL, "test-model", "", "")
+	got := c.buildMode(agent)
+	if got.RoleDefinition != want.RoleDefinition || got.WhenToUse != want.WhenToUse || got.Description != want.Description {
+		t.Errorf("Expected heuristic fields when the enricher fails, got %+v", got)
+	}
+}
+
+func TestConvertSource_EnricherRepairsFrontmatter(t *testing.T) {
+	server, _ := fakeCompletions(t, "```yaml\nname: broken-agent\ndescription: Plans releases\n```", `{}`)
+	path := filepath.Join(t.TempDir(), "broken-agent.md")
+	content := "---\nname: broken-agent\ndescription: [Plans releases\n---\nYou plan releases.\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := NewConverter().convertSource(path); err == nil {
+		t.Fatal("Expected the frontmatter to be unrepairable without an enricher")
+	}
+
+	c := NewConverter()
+	c.enricher = NewOpenAIEnricher(server.URL, "test-model", "", "")
+	modes, record, err := c.convertSource(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modes[0].Slug != "broken-agent" {
+		t.Errorf("Unexpected mode: %+v", modes[0])
+	}
+	if record == nil || len(record.Strategies) != 1 || record.Strategies[0] != enricherRepairStrategy {
+		t.Errorf("Expected an %s sanitization record, got %+v", enricherRepairStrategy, record)
+	}
+}
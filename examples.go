@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AgentExample is one <example> block parsed from a Claude agent description
+type AgentExample struct {
+	Context    string `yaml:"context,omitempty" json:"context,omitempty"`
+	User       string `yaml:"user,omitempty" json:"user,omitempty"`
+	Assistant  string `yaml:"assistant,omitempty" json:"assistant,omitempty"`
+	Commentary string `yaml:"commentary,omitempty" json:"commentary,omitempty"`
+}
+
+var (
+	exampleBlockRe  = regexp.MustCompile(`(?is)<example>(.*?)</example>`)
+	commentaryRe    = regexp.MustCompile(`(?is)<commentary>(.*?)</commentary>`)
+	exampleLabelRe  = regexp.MustCompile(`(?i)(?:^|\s)(context|user|assistant):\s*`)
+	examplesHeading = regexp.MustCompile(`(?i)\s*(?:for\s+)?examples?\s*:?\s*$`)
+	whitespaceRe    = regexp.MustCompile(`\s+`)
+)
+
+// extractExamples splits a description into its lead text and its <example> blocks.
+// Descriptions without examples are returned unchanged.
+func extractExamples(description string) (string, []AgentExample) {
+	if !exampleBlockRe.MatchString(description) {
+		return description, nil
+	}
+
+	// Claude descriptions often carry escaped newlines from single-line YAML
+	text := strings.ReplaceAll(description, `\n`, "\n")
+
+	var examples []AgentExample
+	for _, match := range exampleBlockRe.FindAllStringSubmatch(text, -1) {
+		examples = append(examples, parseExample(match[1]))
+	}
+
+	// The lead is everything before the first example, minus an "Examples:" heading
+	lead := text[:exampleBlockRe.FindStringIndex(text)[0]]
+	lead = examplesHeading.ReplaceAllString(lead, "")
+	return collapseWhitespace(lead), examples
+}
+
+// parseExample reads the Context/user/assistant turns and commentary of one example
+func parseExample(block string) AgentExample {
+	var example AgentExample
+	if match := commentaryRe.FindStringSubmatch(block); match != nil {
+		example.Commentary = collapseWhitespace(match[1])
+		block = commentaryRe.ReplaceAllString(block, "")
+	}
+
+	// Only the first turn of each kind is kept; later turns are follow-ups
+	labels := exampleLabelRe.FindAllStringSubmatchIndex(block, -1)
+	for i, label := range labels {
+		end := len(block)
+		if i+1 < len(labels) {
+			end = labels[i+1][0]
+		}
+		value := collapseWhitespace(block[label[1]:end])
+
+		switch strings.ToLower(block[label[2]:label[3]]) {
+		case "context":
+			if example.Context == "" {
+				example.Context = value
+			}
+		case "user":
+			if example.User == "" {
+				example.User = strings.Trim(value, `"`)
+			}
+		case "assistant":
+			if example.Assistant == "" {
+				example.Assistant = strings.Trim(value, `"`)
+			}
+		}
+	}
+	return example
+}
+
+// collapseWhitespace joins lines and runs of spaces into single spaces
+func collapseWhitespace(text string) string {
+	return strings.TrimSpace(whitespaceRe.ReplaceAllString(text, " "))
+}
+
+// trigger condenses an example into a short phrase describing when it applies
+func (e AgentExample) trigger() string {
+	if e.Context != "" {
+		context := e.Context
+		if i := strings.Index(context, ". "); i >= 0 {
+			context = context[:i]
+		}
+		return strings.TrimSuffix(context, ".")
+	}
+	if e.User != "" {
+		return fmt.Sprintf("the user asks %q", e.User)
+	}
+	return ""
+}
+
+// examplesTriggers renders examples as a sentence to append to whenToUse
+func examplesTriggers(examples []AgentExample) string {
+	var triggers []string
+	for _, example := range examples {
+		if trigger := example.trigger(); trigger != "" {
+			triggers = append(triggers, trigger)
+		}
+	}
+	if len(triggers) == 0 {
+		return ""
+	}
+	return "For example: " + strings.Join(triggers, "; ") + "."
+}
+
+// examplesSection renders examples as a markdown section for customInstructions
+func examplesSection(examples []AgentExample) string {
+	var section strings.Builder
+	section.WriteString("## Examples\n")
+	for i, example := range examples {
+		section.WriteString(fmt.Sprintf("\n### Example %d\n\n", i+1))
+		if example.Context != "" {
+			section.WriteString(fmt.Sprintf("- **Context**: %s\n", example.Context))
+		}
+		if example.User != "" {
+			section.WriteString(fmt.Sprintf("- **User**: %s\n", example.User))
+		}
+		if example.Assistant != "" {
+			section.WriteString(fmt.Sprintf("- **Assistant**: %s\n", example.Assistant))
+		}
+		if example.Commentary != "" {
+			section.WriteString(fmt.Sprintf("- **Why**: %s\n", example.Commentary))
+		}
+	}
+	return section.String()
+}
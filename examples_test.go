@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const reviewerDescription = `Use this agent when you need a thorough code review after writing code. Examples:\n\n<example>\nContext: The user has just implemented a new authentication endpoint. More detail here.\nuser: "I've finished the login handler"\nassistant: "Let me use the code-reviewer agent to review it"\n<commentary>\nNew code was written, so a review is due.\n</commentary>\n</example>\n\n<example>\nuser: "Can you check my PR?"\nassistant: "I'll launch the code-reviewer agent"\n</example>`
+
+func TestExtractExamples(t *testing.T) {
+	lead, examples := extractExamples(reviewerDescription)
+	if lead != "Use this agent when you need a thorough code review after writing code." {
+		t.Errorf("Unexpected lead: %q", lead)
+	}
+	if len(examples) != 2 {
+		t.Fatalf("Expected 2 examples, got %+v", examples)
+	}
+
+	want := AgentExample{
+		Context:    "The user has just implemented a new authentication endpoint. More detail here.",
+		User:       "I've finished the login handler",
+		Assistant:  "Let me use the code-reviewer agent to review it",
+		Commentary: "New code was written, so a review is due.",
+	}
+	if examples[0] != want {
+		t.Errorf("Unexpected first example: %+v", examples[0])
+	}
+	if examples[1].Context != "" || examples[1].User != "Can you check my PR?" {
+		t.Errorf("Unexpected second example: %+v", examples[1])
+	}
+}
+
+func TestExtractExamples_NoExamples(t *testing.T) {
+	description := "Python expert. Use PROACTIVELY for refactoring."
+	lead, examples := extractExamples(description)
+	if lead != description || examples != nil {
+		t.Errorf("Expected description unchanged, got %q, %+v", lead, examples)
+	}
+}
+
+func TestExamplesTriggers(t *testing.T) {
+	_, examples := extractExamples(reviewerDescription)
+	got := examplesTriggers(examples)
+	want := `For example: The user has just implemented a new authentication endpoint; the user asks "Can you check my PR?".`
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestBuildMode_ExamplesTarget(t *testing.T) {
+	agent := &SourceAgent{Name: "code-reviewer", Description: reviewerDescription, Body: "Review code.", Format: "claude"}
+
+	c := NewConverter()
+	mode := c.buildMode(agent)
+	if strings.Contains(mode.RoleDefinition, "<example>") || !strings.Contains(mode.WhenToUse, "For example: The user has just implemented") {
+		t.Errorf("Expected examples in whenToUse only, got roleDefinition %q, whenToUse %q", mode.RoleDefinition, mode.WhenToUse)
+	}
+	if len(mode.Examples) != 2 {
+		t.Errorf("Expected examples on the mode, got %+v", mode.Examples)
+	}
+
+	c.examples = "instructions"
+	mode = c.buildMode(agent)
+	if strings.Contains(mode.WhenToUse, "For example") {
+		t.Errorf("Expected no triggers in whenToUse, got %q", mode.WhenToUse)
+	}
+	if !strings.HasPrefix(mode.CustomInstructions, "Review code.\n\n## Examples\n\n### Example 1\n\n- **Context**: The user has just") {
+		t.Errorf("Unexpected customInstructions:\n%s", mode.CustomInstructions)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxExplainedCandidates limits how many runners-up -explain prints per decision
+const maxExplainedCandidates = 5
+
+// KeywordMatch is a keyword found in one region of an agent's analyzed text
+type KeywordMatch struct {
+	Keyword string     `json:"keyword"`
+	Region  RegionKind `json:"region,omitempty"`
+	Count   int        `json:"count"`
+	Weight  int        `json:"weight"`
+}
+
+// CandidateScore is the score a heuristic gave one possible value, with the keywords behind it
+type CandidateScore struct {
+	Value   string         `json:"value"`
+	Score   int            `json:"score"`
+	Matches []KeywordMatch `json:"matches,omitempty"`
+}
+
+// DecisionTrace explains how a heuristic chose the value of one mode field
+type DecisionTrace struct {
+	Field      string           `json:"field"`
+	Value      string           `json:"value"`
+	Rule       string           `json:"rule"`
+	Candidates []CandidateScore `json:"candidates,omitempty"`
+	Fallback   string           `json:"fallback,omitempty"` // Why no rule matched, when a default was used
+}
+
+// ModeExplanation collects the decision traces of one converted mode
+type ModeExplanation struct {
+	FilePath  string          `json:"file"`
+	Slug      string          `json:"slug"`
+	Decisions []DecisionTrace `json:"decisions"`
+}
+
+// rankCandidates orders candidates by descending score, keeping the given order for ties
+func rankCandidates(candidates []CandidateScore) []CandidateScore {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	return candidates
+}
+
+// describeMatches renders keyword matches as "python (name ×2, w3)"
+func describeMatches(matches []KeywordMatch) string {
+	parts := make([]string, len(matches))
+	for i, match := range matches {
+		if match.Region == "" {
+			// Classifier terms belong to no single region
+			parts[i] = fmt.Sprintf("%s (w%d)", match.Keyword, match.Weight)
+			continue
+		}
+		parts[i] = fmt.Sprintf("%s (%s ×%d, w%d)", match.Keyword, match.Region, match.Count, match.Weight)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// matchedKeywords lists the distinct keywords among matches, in order
+func matchedKeywords(matches []KeywordMatch) []string {
+	var keywords []string
+	seen := make(map[string]bool)
+	for _, match := range matches {
+		if !seen[match.Keyword] {
+			seen[match.Keyword] = true
+			keywords = append(keywords, match.Keyword)
+		}
+	}
+	return keywords
+}
+
+// explainModes builds the explanations for modes converted from one source file
+func explainModes(filePath string, modes []KiloMode) []ModeExplanation {
+	var explanations []ModeExplanation
+	for _, mode := range modes {
+		if len(mode.Traces) > 0 {
+			explanations = append(explanations, ModeExplanation{FilePath: filePath, Slug: mode.Slug, Decisions: mode.Traces})
+		}
+	}
+	return explanations
+}
+
+// explainFile converts a source file and prints why each heuristic field got its value
+func (c *Converter) explainFile(filePath string, w io.Writer) error {
+	modes, _, err := c.convertSource(filePath)
+	for i, explanation := range explainModes(filepath.Base(filePath), modes) {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		writeExplanation(w, explanation)
+	}
+	return err
+}
+
+// writeExplanation prints one mode's decision traces
+func writeExplanation(w io.Writer, explanation ModeExplanation) {
+	fmt.Fprintf(w, "%s → %s\n", explanation.FilePath, explanation.Slug)
+	for _, trace := range explanation.Decisions {
+		fmt.Fprintf(w, "\n  %s: %s\n", trace.Field, trace.Value)
+		fmt.Fprintf(w, "    rule: %s\n", trace.Rule)
+		if trace.Fallback != "" {
+			fmt.Fprintf(w, "    fallback: %s\n", trace.Fallback)
+		}
+
+		shown := 0
+		for _, candidate := range trace.Candidates {
+			if shown == maxExplainedCandidates {
+				break
+			}
+			if candidate.Score == 0 {
+				continue
+			}
+			if shown == 0 {
+				fmt.Fprintf(w, "    candidates:\n")
+			}
+			fmt.Fprintf(w, "      %5d  %s\n", candidate.Score, candidate.Value)
+			if len(candidate.Matches) > 0 {
+				fmt.Fprintf(w, "             %s\n", describeMatches(candidate.Matches))
+			}
+			shown++
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSelectIcon_TraceRanksCandidates(t *testing.T) {
+	is := NewIconSelector()
+	icon, trace := is.selectIcon(newAnalysisText("", "Builds Python services", ""))
+
+	if trace.Value != icon || trace.Field != "iconName" {
+		t.Errorf("Expected trace for %s, got %+v", icon, trace)
+	}
+	if len(trace.Candidates) == 0 || trace.Candidates[0].Value != icon {
+		t.Fatalf("Expected the winner to lead the candidates, got %+v", trace.Candidates)
+	}
+	for i := 1; i < len(trace.Candidates); i++ {
+		if trace.Candidates[i].Score > trace.Candidates[i-1].Score {
+			t.Errorf("Expected candidates in descending score order, got %+v", trace.Candidates)
+		}
+	}
+	match := trace.Candidates[0].Matches[0]
+	if match.Region != RegionDescription || match.Weight != domainRegionWeights[RegionDescription] {
+		t.Errorf("Expected a description match with the domain weight, got %+v", match)
+	}
+}
+
+func TestSelectIcon_TraceRecordsFallback(t *testing.T) {
+	_, trace := NewIconSelector().selectIcon(newAnalysisText("unknownrole", "", ""))
+	if trace.Fallback == "" || trace.Rule != "default icon" {
+		t.Errorf("Expected a default-icon fallback, got %+v", trace)
+	}
+}
+
+func TestDetermineGroups_TraceNamesRule(t *testing.T) {
+	c := NewConverter()
+	_, trace := c.determineGroups(newAnalysisText("api-designer", "Designs REST APIs", "Implement endpoints, then run the test suite."), nil)
+	if trace.Value != "read, edit, command" || !strings.HasPrefix(trace.Rule, "least privilege") || !strings.Contains(trace.Rule, "command (run test)") {
+		t.Errorf("Expected edit and command with their evidence, got %+v", trace)
+	}
+}
+
+func TestExplainFile(t *testing.T) {
+	var out strings.Builder
+	if err := NewConverter().explainFile(filepath.Join("testdata", "classification", "api-designer.md"), &out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"api-designer.md → api-designer", "groups: read\n", "rule: least privilege: read only", "iconName: ", "description: Backend development", "whenToUse: ", "rule: ", "candidates:", "(description ×1, w"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %q in explanation:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestConvertDirectory_JSONReportIncludesExplanations(t *testing.T) {
+	in, out := t.TempDir(), t.TempDir()
+	agent := "---\nname: api-designer\ndescription: Designs REST APIs\n---\nYou design APIs.\n"
+	if err := os.WriteFile(filepath.Join(in, "api-designer.md"), []byte(agent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewConverter().convertDirectory(in, out, false, false); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(in, "conversion-diagnostic-report.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report DiagnosticReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.SuccessfulFiles != 1 || len(report.Explanations) != 1 {
+		t.Fatalf("Expected one explained mode, got %+v", report)
+	}
+	explanation := report.Explanations[0]
+	if explanation.FilePath != "api-designer.md" || explanation.Slug != "api-designer" || len(explanation.Decisions) != 4 {
+		t.Errorf("Unexpected explanation %+v", explanation)
+	}
+}
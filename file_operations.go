@@ -1,16 +1,148 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
-// saveModeConfig saves the Kilo Code mode configuration as YAML
+// marshalModes encodes a custom modes file in the configured output format
+func (c *Converter) marshalModes(customModesFile CustomModesFile) ([]byte, error) {
+	if c.outputFormat == "json" {
+		// Kilo's JSON form; keep <example> tags and quotes readable instead of \u-escaped
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(customModesFile); err != nil {
+			return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+
+	// Multi-line fields are written as literal blocks, so Markdown keeps its line breaks
+	// and a split roleDefinition's headings and lists stay inside the scalar
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(customModesFile); err != nil {
+		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// outputExt returns the file extension for the configured output format
+func (c *Converter) outputExt() string {
+	if c.outputFormat == "json" {
+		return ".json"
+	}
+	return ".yaml"
+}
+
+// formatName returns the display name of the configured output format
+func (c *Converter) formatName() string {
+	return strings.ToUpper(strings.TrimPrefix(c.outputExt(), "."))
+}
+
+// claudeSidecar is the file written next to a mode when -claude-extras=sidecar
+type claudeSidecar struct {
+	Slug    string                 `yaml:"slug" json:"slug"`
+	XClaude map[string]interface{} `yaml:"x-claude" json:"x-claude"`
+}
+
+// saveExtrasSidecar writes Claude frontmatter without a Kilo equivalent next to the mode file
+func (c *Converter) saveExtrasSidecar(mode KiloMode, outputDir string) error {
+	if c.claudeExtras != "sidecar" || len(mode.ClaudeExtras) == 0 {
+		return nil
+	}
+
+	sidecar := claudeSidecar{Slug: mode.Slug, XClaude: mode.ClaudeExtras}
+	var data []byte
+	var err error
+	if c.outputFormat == "json" {
+		data, err = json.MarshalIndent(sidecar, "", "  ")
+	} else {
+		data, err = yaml.Marshal(sidecar)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal extras for %s: %w", mode.Slug, err)
+	}
+
+	sidecarFile := filepath.Join(outputDir, mode.Slug+".claude"+c.outputExt())
+	if err := os.WriteFile(sidecarFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write extras sidecar: %w", err)
+	}
+	return nil
+}
+
+// saveCompanionFiles writes the files that accompany a mode file in dir: extras sidecar and rule files
+func (c *Converter) saveCompanionFiles(mode KiloMode, dir string) error {
+	if err := c.saveExtrasSidecar(mode, dir); err != nil {
+		return err
+	}
+	return c.saveRuleFiles(mode, dir)
+}
+
+// saveRuleFiles writes a mode's rule files to .kilocode/rules-<slug>/ under dir
+func (c *Converter) saveRuleFiles(mode KiloMode, dir string) error {
+	if len(mode.RuleFiles) == 0 {
+		return nil
+	}
+
+	rulesDir := filepath.Join(dir, ".kilocode", "rules-"+mode.Slug)
+	if err := os.MkdirAll(rulesDir, 0755); err != nil {
+		return fmt.Errorf("failed to create rules directory: %w", err)
+	}
+	for _, rule := range mode.RuleFiles {
+		if err := os.WriteFile(filepath.Join(rulesDir, rule.Name), []byte(rule.Content), 0644); err != nil {
+			return fmt.Errorf("failed to write rule file: %w", err)
+		}
+	}
+	return nil
+}
+
+// unmappedKeysIssue reports frontmatter keys that have no Kilo mode equivalent
+func (c *Converter) unmappedKeysIssue(mode KiloMode, filePath string) *FileIssue {
+	if len(mode.ClaudeExtras) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(mode.ClaudeExtras))
+	for key := range mode.ClaudeExtras {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	issue := &FileIssue{
+		FilePath:    filePath,
+		IssueType:   IssueUnmappedKeys,
+		Description: fmt.Sprintf("%s: no Kilo equivalent for %s", mode.Slug, strings.Join(keys, ", ")),
+		Plugin:      mode.Plugin,
+	}
+	switch c.claudeExtras {
+	case "inline":
+		issue.Suggestion = "Preserved in the mode's x-claude block"
+	case "sidecar":
+		issue.Suggestion = fmt.Sprintf("Preserved in %s.claude%s", mode.Slug, c.outputExt())
+	default:
+		issue.Suggestion = "Dropped; use -claude-extras inline or sidecar to keep them"
+	}
+	return issue
+}
+
+// saveModeConfig saves the Kilo Code mode configuration as YAML or JSON
 func (c *Converter) saveModeConfig(modes []KiloMode, outputDir, filename string) (string, error) {
 	// Ensure output directory exists
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
@@ -22,40 +154,27 @@ func (c *Converter) saveModeConfig(modes []KiloMode, outputDir, filename string)
 		CustomModes: modes,
 	}
 
-	// Marshal to YAML
-	yamlData, err := yaml.Marshal(customModesFile)
+	data, err := c.marshalModes(customModesFile)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal YAML: %w", err)
+		return "", err
 	}
 
-	// Post-process YAML to match standard Kilo format
-	yamlString := string(yamlData)
-
-	// Use folded scalar format for multi-line strings (>- instead of |-)
-	yamlString = strings.ReplaceAll(yamlString, "roleDefinition: |-", "roleDefinition:")
-	yamlString = strings.ReplaceAll(yamlString, "customInstructions: |-", "customInstructions: >-")
-	yamlString = strings.ReplaceAll(yamlString, "roleDefinition: |", "roleDefinition:")
-	yamlString = strings.ReplaceAll(yamlString, "customInstructions: |", "customInstructions: >-")
-
-	// Fix indentation to match standard format (2 spaces instead of 4)
-	lines := strings.Split(yamlString, "\n")
-	for i, line := range lines {
-		if strings.HasPrefix(line, "    ") {
-			lines[i] = "  " + strings.TrimPrefix(line, "    ")
-		}
+	// Save modes file
+	outputFile := filepath.Join(outputDir, filename)
+	if err := os.WriteFile(outputFile, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s file: %w", c.formatName(), err)
 	}
-	yamlString = strings.Join(lines, "\n")
 
-	// Save YAML file
-	outputFile := filepath.Join(outputDir, filename)
-	if err := os.WriteFile(outputFile, []byte(yamlString), 0644); err != nil {
-		return "", fmt.Errorf("failed to write YAML file: %w", err)
+	for _, mode := range modes {
+		if err := c.saveCompanionFiles(mode, outputDir); err != nil {
+			return "", err
+		}
 	}
 
 	return outputFile, nil
 }
 
-// saveSingleModeConfig saves a single Kilo Code mode configuration as YAML
+// saveSingleModeConfig saves a single Kilo Code mode configuration as YAML or JSON
 func (c *Converter) saveSingleModeConfig(mode KiloMode, outputDir string) (string, error) {
 	// Ensure output directory exists
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
@@ -67,40 +186,24 @@ func (c *Converter) saveSingleModeConfig(mode KiloMode, outputDir string) (strin
 		CustomModes: []KiloMode{mode},
 	}
 
-	// Marshal to YAML
-	yamlData, err := yaml.Marshal(customModesFile)
+	data, err := c.marshalModes(customModesFile)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal YAML: %w", err)
-	}
-
-	// Post-process YAML to match standard Kilo format
-	yamlString := string(yamlData)
-
-	// Use folded scalar format for multi-line strings (>- instead of |-)
-	yamlString = strings.ReplaceAll(yamlString, "roleDefinition: |-", "roleDefinition:")
-	yamlString = strings.ReplaceAll(yamlString, "customInstructions: |-", "customInstructions: >-")
-	yamlString = strings.ReplaceAll(yamlString, "roleDefinition: |", "roleDefinition:")
-	yamlString = strings.ReplaceAll(yamlString, "customInstructions: |", "customInstructions: >-")
-
-	// Fix indentation to match standard format (2 spaces instead of 4)
-	lines := strings.Split(yamlString, "\n")
-	for i, line := range lines {
-		if strings.HasPrefix(line, "    ") {
-			lines[i] = "  " + strings.TrimPrefix(line, "    ")
-		}
+		return "", err
 	}
-	yamlString = strings.Join(lines, "\n")
 
 	// Generate filename based on mode slug
-	outputFile := filepath.Join(outputDir, mode.Slug+".yaml")
-	if err := os.WriteFile(outputFile, []byte(yamlString), 0644); err != nil {
-		return "", fmt.Errorf("failed to write YAML file: %w", err)
+	outputFile := filepath.Join(outputDir, mode.Slug+c.outputExt())
+	if err := os.WriteFile(outputFile, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s file: %w", c.formatName(), err)
+	}
+	if err := c.saveCompanionFiles(mode, outputDir); err != nil {
+		return "", err
 	}
 
 	return outputFile, nil
 }
 
-// saveSingleModeConfigWithPath saves a single Kilo Code mode configuration as YAML with preserved folder structure
+// saveSingleModeConfigWithPath saves a single Kilo Code mode configuration with preserved folder structure
 func (c *Converter) saveSingleModeConfigWithPath(mode KiloMode, inputPath, inputDir, outputDir string) (string, error) {
 	// Calculate the relative path from the input directory
 	relPath, err := filepath.Rel(inputDir, inputPath)
@@ -130,45 +233,35 @@ func (c *Converter) saveSingleModeConfigWithPath(mode KiloMode, inputPath, input
 		CustomModes: []KiloMode{mode},
 	}
 
-	// Marshal to YAML
-	yamlData, err := yaml.Marshal(customModesFile)
+	data, err := c.marshalModes(customModesFile)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal YAML: %w", err)
-	}
-
-	// Post-process YAML to match standard Kilo format
-	yamlString := string(yamlData)
-
-	// Use folded scalar format for multi-line strings (>- instead of |-)
-	yamlString = strings.ReplaceAll(yamlString, "roleDefinition: |-", "roleDefinition:")
-	yamlString = strings.ReplaceAll(yamlString, "customInstructions: |-", "customInstructions: >-")
-	yamlString = strings.ReplaceAll(yamlString, "roleDefinition: |", "roleDefinition:")
-	yamlString = strings.ReplaceAll(yamlString, "customInstructions: |", "customInstructions: >-")
-
-	// Fix indentation to match standard format (2 spaces instead of 4)
-	lines := strings.Split(yamlString, "\n")
-	for i, line := range lines {
-		if strings.HasPrefix(line, "    ") {
-			lines[i] = "  " + strings.TrimPrefix(line, "    ")
-		}
+		return "", err
 	}
-	yamlString = strings.Join(lines, "\n")
 
 	// Generate filename based on mode slug
-	outputFile := filepath.Join(fullOutputDir, mode.Slug+".yaml")
-	if err := os.WriteFile(outputFile, []byte(yamlString), 0644); err != nil {
-		return "", fmt.Errorf("failed to write YAML file: %w", err)
+	outputFile := filepath.Join(fullOutputDir, mode.Slug+c.outputExt())
+	if err := os.WriteFile(outputFile, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s file: %w", c.formatName(), err)
+	}
+	if err := c.saveCompanionFiles(mode, fullOutputDir); err != nil {
+		return "", err
 	}
 
 	return outputFile, nil
 }
 
-// convertFile converts a single file
+// convertFile converts a single file. Modes rejected by policy or strict mode are left out
+// and returned as the error next to the file written with the remaining modes.
 func (c *Converter) convertFile(inputFile, outputDir string) (string, error) {
-	mode, err := c.convertAgent(inputFile)
-	if err != nil {
-		return "", err
+	modes, _, rejected := c.convertSource(inputFile)
+	if len(modes) == 0 {
+		return "", rejected
 	}
+	setModes := make([]*KiloMode, len(modes))
+	for i := range modes {
+		setModes[i] = &modes[i]
+	}
+	c.makeIconsUnique(setModes)
 
 	// For single file processing, we need to preserve the folder structure
 	// Get the absolute path of the input file
@@ -199,89 +292,184 @@ func (c *Converter) convertFile(inputFile, outputDir string) (string, error) {
 			baseName := strings.TrimSuffix(parts[len(parts)-1], filepath.Ext(parts[len(parts)-1]))
 
 			if relativeDir != "" {
-				relativeOutputPath = filepath.Join(relativeDir, baseName+".yaml")
+				relativeOutputPath = filepath.Join(relativeDir, baseName+c.outputExt())
 			} else {
-				relativeOutputPath = baseName + ".yaml"
+				relativeOutputPath = baseName + c.outputExt()
 			}
 		} else {
 			// Fallback to just the base name
 			baseName := strings.TrimSuffix(filepath.Base(inputFile), filepath.Ext(inputFile))
-			relativeOutputPath = baseName + ".yaml"
+			relativeOutputPath = baseName + c.outputExt()
 		}
 	} else {
 		// If not in claude-agents directory, just use the base name
 		baseName := strings.TrimSuffix(filepath.Base(inputFile), filepath.Ext(inputFile))
-		relativeOutputPath = baseName + ".yaml"
+		relativeOutputPath = baseName + c.outputExt()
 	}
 
 	// Create the full output directory path
 	fullOutputDir := filepath.Join(outputDir, filepath.Dir(relativeOutputPath))
 	outputFilename := filepath.Base(relativeOutputPath)
 
-	modes := []KiloMode{*mode}
-	return c.saveModeConfig(modes, fullOutputDir, outputFilename)
+	outputFile, err := c.saveModeConfig(modes, fullOutputDir, outputFilename)
+	if err != nil {
+		return "", err
+	}
+	return outputFile, rejected
+}
+
+// indentLines prefixes every line of text with indent
+func indentLines(text, indent string) string {
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = indent + line
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// convertedFile holds the modes converted from one source file until the set is written
+type convertedFile struct {
+	path, relPath string
+	modes         []KiloMode
 }
 
-// convertDirectory converts all .md files in a directory
+// dropDuplicateSlugs keeps the first mode converted for each slug and returns an issue
+// for every later one it removes
+func dropDuplicateSlugs(converted []convertedFile) []FileIssue {
+	var issues []FileIssue
+	firstSource := make(map[string]string)
+	for i := range converted {
+		file := &converted[i]
+		kept := file.modes[:0]
+		for _, mode := range file.modes {
+			if first, taken := firstSource[mode.Slug]; taken {
+				issues = append(issues, FileIssue{
+					FilePath:    file.relPath,
+					IssueType:   IssueDuplicateSlug,
+					Description: fmt.Sprintf("%s: slug already used by the mode from %s", mode.Slug, first),
+					Suggestion:  issueSuggestions[IssueDuplicateSlug],
+					Plugin:      mode.Plugin,
+				})
+				continue
+			}
+			firstSource[mode.Slug] = file.relPath
+			kept = append(kept, mode)
+		}
+		file.modes = kept
+	}
+	return issues
+}
+
+// convertDirectory converts all recognized agent files in a directory
 func (c *Converter) convertDirectory(inputDir, outputDir string, dryRun bool, singleFiles bool) error {
 	var successful, total, sanitized int
+	lossy := make(map[string]bool) // Converted files that lost modes to strict mode, policy or duplicate slugs
 	var allModes []KiloMode
-	var issues []FileIssue
+	issues := append([]FileIssue(nil), c.iconIssues...)
+	var repairs []SanitizedFile
+	var explanations []ModeExplanation
+	var converted []convertedFile
+	c.inputRoot = inputDir
+
+	// Claude Code plugins and marketplaces are converted with per-plugin namespaces
+	plugins, err := discoverPlugins(inputDir)
+	if err != nil {
+		return err
+	}
+	c.plugins = plugins
+	pluginSummaries := make(map[*pluginInfo]*PluginSummary)
+	for _, plugin := range plugins {
+		pluginSummaries[plugin] = newPluginSummary(plugin, inputDir)
+	}
+
+	// The output of an earlier run inside the input directory is not read back as agents
+	absOutputDir, err := filepath.Abs(outputDir)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path for output directory: %w", err)
+	}
 
-	err := filepath.WalkDir(inputDir, func(path string, d fs.DirEntry, err error) error {
+	err = filepath.WalkDir(inputDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
-		if d.IsDir() || !strings.HasSuffix(strings.ToLower(d.Name()), ".md") {
+		if d.IsDir() {
+			if absPath, err := filepath.Abs(path); err == nil && absPath == absOutputDir && path != inputDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isCandidateFile(d.Name()) {
+			return nil
+		}
+
+		// Inside a plugin only its agents are modes; commands, hooks and manifests are not
+		plugin := findPlugin(plugins, path)
+		if plugin != nil && !plugin.ownsAgent(path) {
+			return nil
+		}
+
+		modes, sanitization, err := c.convertSource(path)
+		if errors.Is(err, errUnsupportedFormat) {
+			// Not an agent file for any reader (e.g. unrelated JSON or YAML)
 			return nil
 		}
 
 		total++
+		relPath := strings.TrimPrefix(path, inputDir+string(filepath.Separator))
+		var pluginSummary *PluginSummary
+		if plugin != nil {
+			pluginSummary = pluginSummaries[plugin]
+			pluginSummary.Files++
+		}
 
-		mode, wasSanitized, err := c.convertAgentWithStats(path)
 		if err != nil {
-			// Record the issue for diagnostic report
-			issue := FileIssue{
-				FilePath:    strings.TrimPrefix(path, inputDir+string(filepath.Separator)),
-				IssueType:   "Conversion Error",
-				Description: err.Error(),
-				Suggestion:  "Check YAML frontmatter syntax and required fields",
+			// Record one issue per rejected mode for the diagnostic report
+			for _, modeErr := range rejectedModes(err) {
+				issue := newFileIssue(relPath, modeErr)
+				if plugin != nil {
+					issue.Plugin = plugin.Name
+				}
+				issues = append(issues, issue)
+
+				if dryRun {
+					fmt.Printf("  ✗ %s → Error: %v\n", d.Name(), modeErr)
+				} else {
+					fmt.Printf("✗ Failed to convert %s: %v\n", d.Name(), modeErr)
+				}
+				if snippet := errorSnippet(modeErr); snippet != "" {
+					fmt.Print(indentLines(snippet, "    "))
+				}
 			}
-			issues = append(issues, issue)
 
-			if dryRun {
-				fmt.Printf("  ✗ %s → Error: %v\n", d.Name(), err)
-			} else {
-				fmt.Printf("✗ Failed to convert %s: %v\n", d.Name(), err)
+			// A multi-mode file keeps the modes that were not rejected
+			if len(modes) == 0 {
+				if plugin != nil {
+					pluginSummary.Failed = append(pluginSummary.Failed, relPath)
+				}
+				return nil
 			}
-			return nil
+			lossy[relPath] = true
 		}
 
-		if wasSanitized {
+		if sanitization != nil {
 			sanitized++
+			repairs = append(repairs, SanitizedFile{
+				FilePath:   relPath,
+				Strategies: sanitization.Strategies,
+				Keys:       sanitization.Keys,
+				Diff:       sanitization.Diff(relPath),
+			})
 		}
 
-		if dryRun {
-			if singleFiles {
-				fmt.Printf("  ✓ %s → %s (in %s.yaml)\n", d.Name(), mode.Slug, mode.Slug)
-			} else {
-				fmt.Printf("  ✓ %s → %s (in custom_modes.yaml)\n", d.Name(), mode.Slug)
-			}
-		} else {
-			if singleFiles {
-				// Save individual file with preserved folder structure
-				outputFile, err := c.saveSingleModeConfigWithPath(*mode, path, inputDir, outputDir)
-				if err != nil {
-					fmt.Printf("✗ Failed to save %s: %v\n", mode.Slug, err)
-					return nil
-				}
-				fmt.Printf("✓ Converted %s → %s\n", d.Name(), outputFile)
-			} else {
-				allModes = append(allModes, *mode)
-				fmt.Printf("✓ Converted %s → %s\n", d.Name(), mode.Slug)
+		if plugin != nil {
+			pluginSummary.Converted++
+			for _, mode := range modes {
+				pluginSummary.Modes = append(pluginSummary.Modes, mode.Slug)
 			}
 		}
+
+		converted = append(converted, convertedFile{path: path, relPath: relPath, modes: modes})
 		successful++
 
 		return nil
@@ -291,23 +479,93 @@ func (c *Converter) convertDirectory(inputDir, outputDir string, dryRun bool, si
 		return err
 	}
 
+	// A slug names one mode, so later modes with a taken slug are dropped before anything is written
+	for _, issue := range dropDuplicateSlugs(converted) {
+		fmt.Printf("✗ Skipped %s: %s\n", filepath.Base(issue.FilePath), issue.Description)
+		issues = append(issues, issue)
+		lossy[issue.FilePath] = true
+	}
+
+	// Icons are made unique across the whole set before anything is written
+	var setModes []*KiloMode
+	for _, file := range converted {
+		for i := range file.modes {
+			setModes = append(setModes, &file.modes[i])
+		}
+	}
+	iconDuplicates := c.makeIconsUnique(setModes)
+
+	for _, file := range converted {
+		explanations = append(explanations, explainModes(file.relPath, file.modes)...)
+		for i := range file.modes {
+			mode := &file.modes[i]
+			mode.SourceFile = filepath.ToSlash(file.relPath)
+			if issue := c.unmappedKeysIssue(*mode, file.relPath); issue != nil {
+				issues = append(issues, *issue)
+			}
+			issues = append(issues, policyIssues(*mode, file.relPath)...)
+			if dryRun {
+				if singleFiles {
+					fmt.Printf("  ✓ %s → %s (in %s%s)\n", filepath.Base(file.path), mode.Slug, mode.Slug, c.outputExt())
+				} else {
+					fmt.Printf("  ✓ %s → %s (in custom_modes%s)\n", filepath.Base(file.path), mode.Slug, c.outputExt())
+				}
+				for _, rule := range mode.RuleFiles {
+					fmt.Printf("      + .kilocode/rules-%s/%s\n", mode.Slug, rule.Name)
+				}
+			} else {
+				allModes = append(allModes, *mode)
+				if singleFiles {
+					// Save individual file with preserved folder structure
+					outputFile, err := c.saveSingleModeConfigWithPath(*mode, file.path, inputDir, outputDir)
+					if err != nil {
+						fmt.Printf("✗ Failed to save %s: %v\n", mode.Slug, err)
+						continue
+					}
+					fmt.Printf("✓ Converted %s → %s\n", filepath.Base(file.path), outputFile)
+				} else {
+					fmt.Printf("✓ Converted %s → %s\n", filepath.Base(file.path), mode.Slug)
+				}
+			}
+		}
+	}
+
+	var summaries []PluginSummary
+	for _, plugin := range plugins {
+		summaries = append(summaries, *pluginSummaries[plugin])
+	}
+
 	// Generate diagnostic report
-	if err := GenerateDiagnosticReport(inputDir, issues, total, successful, sanitized); err != nil {
+	report := DiagnosticReport{
+		TotalFiles:      total,
+		SuccessfulFiles: successful,
+		FailedFiles:     total - successful,
+		SanitizedFiles:  sanitized,
+		Sanitized:       repairs,
+		Issues:          issues,
+		Plugins:         summaries,
+		Explanations:    explanations,
+		IconDuplicates:  iconDuplicates,
+		Timestamp:       time.Now(),
+	}
+	if err := SaveDiagnosticReport(inputDir, report); err != nil {
 		fmt.Printf("Warning: Failed to generate diagnostic report: %v\n", err)
 	}
 
+	printPluginSummaries(summaries)
+
 	if dryRun {
 		if singleFiles {
-			fmt.Printf("Would convert %d files to individual YAML files\n", successful)
+			fmt.Printf("Would convert %d files to individual %s files\n", successful, c.formatName())
 		} else {
-			fmt.Printf("Would convert %d files to custom_modes.yaml\n", successful)
+			fmt.Printf("Would convert %d files to custom_modes%s\n", successful, c.outputExt())
 		}
 		if sanitized > 0 {
 			fmt.Printf("Note: %d files would require YAML sanitization\n", sanitized)
 		}
 	} else if !singleFiles && len(allModes) > 0 {
 		// Only save combined file if not in single files mode
-		outputFile, err := c.saveModeConfig(allModes, outputDir, "custom_modes.yaml")
+		outputFile, err := c.saveModeConfig(allModes, outputDir, "custom_modes"+c.outputExt())
 		if err != nil {
 			return fmt.Errorf("failed to save modes: %w", err)
 		}
@@ -325,5 +583,18 @@ func (c *Converter) convertDirectory(inputDir, outputDir string, dryRun bool, si
 		fmt.Printf("Output directory: %s\n", outputDir)
 	}
 
+	if !dryRun && len(c.catalog) > 0 && len(allModes) > 0 {
+		catalogPath, err := c.writeCatalog(allModes, outputDir, c.catalog)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Catalog: %s\n", catalogPath)
+	}
+
+	// Strict mode guarantees a clean library, so any failed file or rejected mode fails the run
+	if c.strict && (successful < total || len(lossy) > 0) {
+		return fmt.Errorf("strict mode: %d of %d files could not be converted without loss", total-successful+len(lossy), total)
+	}
+
 	return nil
 }
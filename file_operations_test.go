@@ -1,8 +1,13 @@
 package main
 
 import (
+	"encoding/json"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"gopkg.in/yaml.v3"
 )
 
 type dummyConverter struct{ Converter }
@@ -56,3 +61,123 @@ func TestSaveModeConfig_ErrorOnBadDir(t *testing.T) {
 		t.Error("Expected error for bad directory, got nil")
 	}
 }
+
+func TestSaveModeConfig_JSONFormat(t *testing.T) {
+	c := NewConverter()
+	c.outputFormat = "json"
+	instructions := "## Approach\n\n- Step one: \"quoted\"\n<example>user: hi</example>"
+	modes := []KiloMode{{Slug: "test", Name: "Test", IconName: "codicon-gear", RoleDefinition: "desc", Description: "desc", Groups: []string{"read"}, CustomInstructions: instructions, Source: "project", OriginalModel: "opus"}}
+	dir := t.TempDir()
+	file, err := c.saveModeConfig(modes, dir, "custom_modes"+c.outputExt())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !strings.HasSuffix(file, ".json") {
+		t.Errorf("Expected .json file, got %s", file)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if strings.Contains(string(data), `\u003c`) || !strings.Contains(string(data), "<example>") {
+		t.Error("Expected HTML characters to be left unescaped")
+	}
+	var decoded CustomModesFile
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Expected valid JSON, got: %v", err)
+	}
+	if decoded.CustomModes[0].CustomInstructions != instructions {
+		t.Errorf("Expected multi-line instructions to round-trip, got %q", decoded.CustomModes[0].CustomInstructions)
+	}
+	again, _ := c.marshalModes(CustomModesFile{CustomModes: modes})
+	if string(again) != string(data) {
+		t.Error("Expected stable JSON output across runs")
+	}
+}
+
+func TestMarshalModes_YAMLRoundTrip(t *testing.T) {
+	c := NewConverter()
+	instructions := "## Approach\n\n1. Start with the failing test.\n2. Run the suite.\n\n    indented code"
+	modes := []KiloMode{{Slug: "test", Name: "Test", IconName: "codicon-gear", RoleDefinition: "desc", Description: "desc", Groups: []string{"read"}, CustomInstructions: instructions, Source: "project"}}
+	data, err := c.marshalModes(CustomModesFile{CustomModes: modes})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded CustomModesFile
+	if err := yaml.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Expected valid YAML, got: %v\n%s", err, data)
+	}
+	if decoded.CustomModes[0].CustomInstructions != instructions {
+		t.Errorf("Expected multi-line instructions to round-trip, got %q", decoded.CustomModes[0].CustomInstructions)
+	}
+}
+
+func TestClaudeExtras_InlineAndSidecar(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, "agent.md", "---\nname: painter\ndescription: Paint things\nmodel: opus\ncolor: purple\n---\nBody.")
+
+	c := NewConverter()
+	c.claudeExtras = "inline"
+	mode, err := c.convertAgent(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if mode.XClaude["color"] != "purple" || mode.XClaude["model"] != "opus" {
+		t.Errorf("Expected x-claude block with color and model, got %+v", mode.XClaude)
+	}
+	data, _ := c.marshalModes(CustomModesFile{CustomModes: []KiloMode{*mode}})
+	if !strings.Contains(string(data), "x-claude:") {
+		t.Error("Expected x-claude block in YAML output")
+	}
+
+	c.claudeExtras = "sidecar"
+	mode, _ = c.convertAgent(path)
+	out := t.TempDir()
+	if _, err := c.saveSingleModeConfig(*mode, out); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	sidecar, err := os.ReadFile(out + string(os.PathSeparator) + "painter.claude.yaml")
+	if err != nil {
+		t.Fatalf("Expected sidecar file, got: %v", err)
+	}
+	if !strings.Contains(string(sidecar), "color: purple") {
+		t.Errorf("Unexpected sidecar content: %s", sidecar)
+	}
+
+	issue := c.unmappedKeysIssue(*mode, "agent.md")
+	if issue == nil || !strings.Contains(issue.Description, "color, model") {
+		t.Errorf("Expected unmapped keys issue, got %+v", issue)
+	}
+}
+
+func TestConvertDirectory_SkipsOutputAndDuplicateSlugs(t *testing.T) {
+	input := t.TempDir()
+	writeTestFile(t, input, "ai-engineer.md", "---\nname: ai-engineer\ndescription: Builds LLM apps\n---\nYou build retrieval pipelines.")
+	writeTestFile(t, input, "copies/ai-engineer.md", "---\nname: ai-engineer\ndescription: Builds LLM apps\n---\nYou build agents.")
+
+	// Running twice into a directory inside the input must not read the first run's output back
+	output := filepath.Join(input, "kilo-modes")
+	for run := 0; run < 2; run++ {
+		if err := NewConverter().convertDirectory(input, output, false, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(output, "custom_modes.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(data), "slug: ai-engineer"); got != 1 {
+		t.Errorf("Expected ai-engineer once, got %d times:\n%s", got, data)
+	}
+	if !strings.Contains(string(data), "You build retrieval pipelines.") {
+		t.Errorf("Expected the first mode converted for the slug to be kept:\n%s", data)
+	}
+	report, err := os.ReadFile(filepath.Join(input, "conversion-diagnostic-report.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(report), "ai-engineer: slug already used by the mode from ai-engineer.md") {
+		t.Errorf("Expected the duplicate slug in the report:\n%s", report)
+	}
+}
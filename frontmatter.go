@@ -0,0 +1,219 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// IssueType classifies problems recorded in the diagnostic report
+type IssueType string
+
+const (
+	IssueConversionError         IssueType = "Conversion Error"
+	IssueMissingFrontmatter      IssueType = "Missing Frontmatter"
+	IssueMissingClosingDelimiter IssueType = "Missing Closing Delimiter"
+	IssueInvalidYAML             IssueType = "Invalid YAML"
+	IssueInvalidTOML             IssueType = "Invalid TOML"
+	IssueMissingName             IssueType = "Missing Name"
+	IssueMissingDescription      IssueType = "Missing Description"
+	IssueStrictMode              IssueType = "Strict Mode Violation"
+	IssueUnmappedKeys            IssueType = "Unmapped Frontmatter Keys"
+	IssueUnknownIcon             IssueType = "Unknown Icon"
+	IssuePolicyViolation         IssueType = "Policy Violation"
+	IssueDuplicateSlug           IssueType = "Duplicate Slug"
+)
+
+var (
+	yamlErrorLineRe = regexp.MustCompile(`line (\d+)(?:, column (\d+))?: `)
+	yamlErrorPrefix = regexp.MustCompile(`^yaml: (?:unmarshal errors:\s*)?(?:line \d+(?:, column \d+)?: )?`)
+)
+
+// FrontmatterError is a frontmatter problem located at a line and column of the source file
+type FrontmatterError struct {
+	Kind    IssueType
+	Path    string
+	Line    int // 1-based line in the source file
+	Column  int // 1-based column, 0 when unknown
+	Message string
+	Snippet string // Source lines around the error with a caret under the column
+}
+
+func (e *FrontmatterError) Error() string {
+	location := fmt.Sprintf("line %d", e.Line)
+	if e.Column > 0 {
+		location += fmt.Sprintf(", column %d", e.Column)
+	}
+	if e.Path != "" {
+		return fmt.Sprintf("%s: %s: %s", e.Path, location, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", location, e.Message)
+}
+
+// frontmatterBlock is the frontmatter and body split out of an agent file
+type frontmatterBlock struct {
+	Content   string // Raw frontmatter between the delimiters
+	Body      string
+	Format    string   // "yaml" for --- delimiters, "toml" for +++
+	StartLine int      // 1-based source line of the first frontmatter line
+	lines     []string // Normalized source lines, for error snippets
+}
+
+// newError builds a FrontmatterError with a snippet for the given source line and column
+func (b *frontmatterBlock) newError(kind IssueType, line, column int, message string) *FrontmatterError {
+	return &FrontmatterError{
+		Kind:    kind,
+		Line:    line,
+		Column:  column,
+		Message: message,
+		Snippet: sourceSnippet(b.lines, line, column),
+	}
+}
+
+// frontmatterDelimiters maps opening delimiter lines to their frontmatter format
+var frontmatterDelimiters = map[string]string{
+	"---": "yaml",
+	"+++": "toml",
+}
+
+// splitFrontmatter separates the YAML or TOML frontmatter block from the markdown body
+func splitFrontmatter(content string) (*frontmatterBlock, error) {
+	// Normalize line endings and drop a UTF-8 byte order mark
+	content = strings.TrimPrefix(content, "\ufeff")
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.ReplaceAll(content, "\r", "\n")
+	lines := strings.Split(content, "\n")
+	block := &frontmatterBlock{lines: lines}
+
+	// Skip leading blank lines and HTML comments (license headers, editor notes)
+	start := 0
+	for start < len(lines) {
+		trimmed := strings.TrimSpace(lines[start])
+		if trimmed == "" {
+			start++
+			continue
+		}
+		if !strings.HasPrefix(trimmed, "<!--") {
+			break
+		}
+		end := commentEnd(lines, start)
+		if end < 0 {
+			return nil, block.newError(IssueMissingFrontmatter, start+1, 1, "unterminated HTML comment before the frontmatter")
+		}
+		start = end + 1
+	}
+	if start == len(lines) {
+		return nil, block.newError(IssueMissingFrontmatter, 1, 0, "no valid frontmatter found - file is empty")
+	}
+
+	delimiter := strings.TrimSpace(lines[start])
+	format, ok := frontmatterDelimiters[delimiter]
+	if !ok {
+		return nil, block.newError(IssueMissingFrontmatter, start+1, 1,
+			fmt.Sprintf("no valid frontmatter found - first line: %q", delimiter))
+	}
+
+	// Find the closing delimiter, tolerating trailing whitespace
+	end := -1
+	for i := start + 1; i < len(lines); i++ {
+		if strings.TrimRight(lines[i], " \t") == delimiter {
+			end = i
+			break
+		}
+	}
+
+	if end < 0 {
+		return nil, block.newError(IssueMissingClosingDelimiter, start+1, 1,
+			fmt.Sprintf("no closing %s found for the frontmatter opened on line %d", delimiter, start+1))
+	}
+
+	block.Content = strings.Join(lines[start+1:end], "\n")
+	block.Body = strings.TrimSpace(strings.Join(lines[end+1:], "\n"))
+	block.Format = format
+	block.StartLine = start + 2
+
+	return block, nil
+}
+
+// commentEnd returns the index of the line closing the HTML comment opened on lines[start], or -1
+func commentEnd(lines []string, start int) int {
+	rest := lines[start][strings.Index(lines[start], "<!--")+4:]
+	for i := start; i < len(lines); i++ {
+		if strings.Contains(rest, "-->") {
+			return i
+		}
+		if i+1 < len(lines) {
+			rest = lines[i+1]
+		}
+	}
+	return -1
+}
+
+// yamlError converts a yaml.v3 error into a FrontmatterError positioned in the source file
+func (b *frontmatterBlock) yamlError(err error, note string) *FrontmatterError {
+	text := err.Error()
+	message := strings.TrimSpace(yamlErrorPrefix.ReplaceAllString(text, ""))
+	if note != "" {
+		message += " (" + note + ")"
+	}
+
+	line, column := b.StartLine, 0
+	if match := yamlErrorLineRe.FindStringSubmatch(text); match != nil {
+		relative, _ := strconv.Atoi(match[1])
+		line = b.StartLine + relative - 1
+		if match[2] != "" {
+			column, _ = strconv.Atoi(match[2])
+		}
+	}
+	if column == 0 && line-1 < len(b.lines) {
+		column = guessColumn(b.lines[line-1], message)
+	}
+
+	return b.newError(IssueInvalidYAML, line, column, message)
+}
+
+// tomlError converts a TOML parse error into a FrontmatterError positioned in the source file
+func (b *frontmatterBlock) tomlError(err error) *FrontmatterError {
+	var parseErr *tomlError
+	if !errors.As(err, &parseErr) {
+		return b.newError(IssueInvalidTOML, b.StartLine, 0, err.Error())
+	}
+	return b.newError(IssueInvalidTOML, b.StartLine+parseErr.Line-1, parseErr.Column, parseErr.Message)
+}
+
+// guessColumn estimates where on a line yaml.v3 stopped, since most of its messages carry no column
+func guessColumn(line, message string) int {
+	switch {
+	case strings.Contains(message, "mapping values are not allowed"):
+		// The offending colon is the first one after the key's own
+		if first := strings.Index(line, ":"); first >= 0 {
+			if second := strings.Index(line[first+1:], ": "); second >= 0 {
+				return first + 1 + second + 1
+			}
+		}
+	case strings.Contains(message, "cannot start any token"):
+		if i := strings.IndexAny(line, "\t`@%"); i >= 0 {
+			return i + 1
+		}
+	}
+	return len(line) - len(strings.TrimLeft(line, " \t")) + 1
+}
+
+// sourceSnippet renders the lines before and at line with a caret under column
+func sourceSnippet(lines []string, line, column int) string {
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+
+	var snippet strings.Builder
+	width := len(strconv.Itoa(line))
+	for i := max(1, line-2); i <= line; i++ {
+		snippet.WriteString(fmt.Sprintf("%*d | %s\n", width, i, lines[i-1]))
+	}
+	if column > 0 {
+		snippet.WriteString(fmt.Sprintf("%s | %s^\n", strings.Repeat(" ", width), strings.Repeat(" ", column-1)))
+	}
+	return snippet.String()
+}
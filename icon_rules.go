@@ -0,0 +1,245 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed codicons.txt
+var embeddedCodicons string
+
+// codiconCSSRe finds icon classes in codicon.css
+var codiconCSSRe = regexp.MustCompile(`\.codicon-([a-z0-9-]+):before`)
+
+// IconRules are user icon rules loaded with -icon-rules
+type IconRules struct {
+	Icons    string                 `yaml:"icons"`    // Codicon list written by "icons update", relative to the rules file
+	Exact    map[string]string      `yaml:"exact"`    // Role name → icon, checked before the built-in roles
+	Patterns []IconPattern          `yaml:"patterns"` // Checked in order after exact roles
+	Keywords map[string]IconKeyword `yaml:"keywords"` // Scored with the built-in domain keywords
+}
+
+// IconPattern selects an icon when a regular expression matches the agent's name
+type IconPattern struct {
+	Match string `yaml:"match"`
+	Icon  string `yaml:"icon"`
+}
+
+// IconKeyword scores an icon when the keyword occurs; Weight multiplies its region weights
+type IconKeyword struct {
+	Icon   string `yaml:"icon"`
+	Weight int    `yaml:"weight"`
+}
+
+// iconPattern is a compiled IconPattern
+type iconPattern struct {
+	re   *regexp.Regexp
+	icon string
+}
+
+// createValidIconsSet creates a set of all valid codicon names from the embedded list
+func createValidIconsSet() map[string]bool {
+	return parseIconList(embeddedCodicons)
+}
+
+// parseIconList reads a codicon list with one name per line
+func parseIconList(list string) map[string]bool {
+	icons := make(map[string]bool)
+	for _, line := range strings.Split(list, "\n") {
+		if name := strings.TrimSpace(line); name != "" && !strings.HasPrefix(name, "#") {
+			icons[name] = true
+		}
+	}
+	return icons
+}
+
+// LoadIconRules reads icon rules from a YAML file
+func LoadIconRules(path string) (*IconRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading icon rules %s: %w", path, err)
+	}
+	var rules IconRules
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("invalid icon rules %s: %w", path, err)
+	}
+	if rules.Icons != "" && !filepath.IsAbs(rules.Icons) {
+		rules.Icons = filepath.Join(filepath.Dir(path), rules.Icons)
+	}
+	return &rules, nil
+}
+
+// ApplyRules adds user rules to the selector. Icons missing from the codicon list are
+// returned as issues against source; the selector skips them like any invalid icon.
+func (is *IconSelector) ApplyRules(rules *IconRules, source string) ([]FileIssue, error) {
+	if rules.Icons != "" {
+		data, err := os.ReadFile(rules.Icons)
+		if err != nil {
+			return nil, fmt.Errorf("error reading codicon list: %w", err)
+		}
+		if is.validIcons = parseIconList(string(data)); len(is.validIcons) == 0 {
+			return nil, fmt.Errorf("codicon list %s is empty", rules.Icons)
+		}
+	}
+
+	var issues []FileIssue
+	check := func(rule, icon string) {
+		if !is.validIcons[icon] {
+			issues = append(issues, FileIssue{
+				FilePath:    source,
+				IssueType:   IssueUnknownIcon,
+				Description: fmt.Sprintf("%s: %s is not a known codicon", rule, icon),
+				Suggestion:  issueSuggestions[IssueUnknownIcon],
+			})
+		}
+	}
+
+	for _, role := range sortedKeys(rules.Exact) {
+		is.exactRoleMap[strings.ToLower(role)] = rules.Exact[role]
+		check(fmt.Sprintf("exact role %q", role), rules.Exact[role])
+	}
+	for _, pattern := range rules.Patterns {
+		re, err := regexp.Compile(pattern.Match)
+		if err != nil {
+			return nil, fmt.Errorf("icon pattern %q: %w", pattern.Match, err)
+		}
+		is.patterns = append(is.patterns, iconPattern{re: re, icon: pattern.Icon})
+		check(fmt.Sprintf("pattern %q", pattern.Match), pattern.Icon)
+	}
+	for _, word := range sortedKeys(rules.Keywords) {
+		keyword := rules.Keywords[word]
+		if keyword.Weight < 0 {
+			return nil, fmt.Errorf("icon keyword %q: weight must not be negative", word)
+		}
+		is.domainKeywords[strings.ToLower(word)] = keyword.Icon
+		if keyword.Weight > 0 {
+			is.keywordWeights[strings.ToLower(word)] = keyword.Weight
+		}
+		check(fmt.Sprintf("keyword %q", word), keyword.Icon)
+	}
+	return issues, nil
+}
+
+// parseCodicons extracts icon names from @vscode/codicons files: codicon.csv, the font
+// mapping JSON (name → code point, or code point → names) or codicon.css
+func parseCodicons(path string, data []byte) ([]string, error) {
+	var names []string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		records, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		column := 0
+		if len(records) > 0 {
+			for i, header := range records[0] {
+				if header == "short_name" || header == "name" {
+					column = i
+					records = records[1:]
+					break
+				}
+			}
+		}
+		for _, record := range records {
+			if column < len(record) {
+				names = append(names, record[column])
+			}
+		}
+	case ".json":
+		var mapping map[string]interface{}
+		if err := json.Unmarshal(data, &mapping); err != nil {
+			return nil, fmt.Errorf("invalid font mapping: %w", err)
+		}
+		for key, value := range mapping {
+			switch value := value.(type) {
+			case float64:
+				names = append(names, key)
+			case []interface{}:
+				for _, name := range value {
+					if name, ok := name.(string); ok {
+						names = append(names, name)
+					}
+				}
+			}
+		}
+	case ".css":
+		for _, match := range codiconCSSRe.FindAllStringSubmatch(string(data), -1) {
+			names = append(names, match[1])
+		}
+	default:
+		return nil, fmt.Errorf("unsupported codicon file %s (use codicon.csv, the font mapping .json or codicon.css)", path)
+	}
+
+	seen := make(map[string]bool)
+	var icons []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !strings.HasPrefix(name, "codicon-") {
+			name = "codicon-" + name
+		}
+		if !seen[name] {
+			seen[name] = true
+			icons = append(icons, name)
+		}
+	}
+	if len(icons) == 0 {
+		return nil, fmt.Errorf("no icon names found in %s", path)
+	}
+	sort.Strings(icons)
+	return icons, nil
+}
+
+// runIcons implements the icons subcommand
+func runIcons(args []string) error {
+	if len(args) == 0 || args[0] != "update" {
+		return fmt.Errorf("usage: %s icons update --from <codicon.csv|mapping.json|codicon.css> [-output file]", os.Args[0])
+	}
+
+	flags := flag.NewFlagSet("icons update", flag.ExitOnError)
+	from := flags.String("from", "", "codicon.csv, the font mapping .json or codicon.css from a local copy of @vscode/codicons")
+	output := flags.String("output", "codicons.txt", "Where to write the codicon list")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s icons update --from <file> [options]\n\nRefresh the list of valid codicon names.\n\nOptions:\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args[1:])
+	if *from == "" {
+		flags.Usage()
+		return fmt.Errorf("--from is required")
+	}
+
+	data, err := os.ReadFile(*from)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", *from, err)
+	}
+	icons, err := parseCodicons(*from, data)
+	if err != nil {
+		return err
+	}
+
+	old := createValidIconsSet()
+	added := 0
+	for _, icon := range icons {
+		if !old[icon] {
+			added++
+		}
+	}
+	if err := os.WriteFile(*output, []byte(strings.Join(icons, "\n")+"\n"), 0644); err != nil {
+		return fmt.Errorf("error writing icon list: %w", err)
+	}
+	fmt.Printf("✓ Wrote %d icons (%d new, %d removed) → %s\n", len(icons), added, len(old)-(len(icons)-added), *output)
+	return nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeIconRules(t *testing.T, rules string) string {
+	path := filepath.Join(t.TempDir(), "icons.yaml")
+	if err := os.WriteFile(path, []byte(rules), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestApplyRules(t *testing.T) {
+	path := writeIconRules(t, `exact:
+  release-manager: codicon-rocket
+patterns:
+  - match: "^k8s-"
+    icon: codicon-organization
+keywords:
+  invoice: {icon: codicon-credit-card, weight: 3}
+`)
+	rules, err := LoadIconRules(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	is := NewIconSelector()
+	issues, err := is.ApplyRules(rules, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("Expected no unknown icons, got %+v", issues)
+	}
+
+	cases := []struct{ name, description, want string }{
+		{"release-manager", "", "codicon-rocket"},
+		{"k8s-operator", "Operates clusters", "codicon-organization"},
+		// The weighted keyword in the description outscores "api" in the name
+		{"api-helper", "Reconciles each invoice", "codicon-credit-card"},
+	}
+	for _, tc := range cases {
+		if icon := is.SelectIcon(tc.name, tc.description, ""); icon != tc.want {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.want, icon)
+		}
+	}
+}
+
+func TestApplyRules_UnknownIcons(t *testing.T) {
+	path := writeIconRules(t, `exact:
+  planner: codicon-calendar
+  lawyer: codicon-gavel
+keywords:
+  invoice: {icon: codicon-money}
+`)
+	rules, err := LoadIconRules(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	is := NewIconSelector()
+	issues, err := is.ApplyRules(rules, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 2 || issues[0].IssueType != IssueUnknownIcon || !strings.Contains(issues[0].Description, "codicon-gavel") {
+		t.Fatalf("Expected unknown icon issues for codicon-gavel and codicon-money, got %+v", issues)
+	}
+	if icon := is.SelectIcon("lawyer", "", ""); icon == "codicon-gavel" {
+		t.Error("Expected an unknown configured icon to be skipped")
+	}
+
+	if _, err := is.ApplyRules(&IconRules{Patterns: []IconPattern{{Match: "(", Icon: "codicon-bug"}}}, path); err == nil {
+		t.Error("Expected an invalid pattern to be rejected")
+	}
+}
+
+func TestApplyRules_IconList(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "codicons.txt"), []byte("codicon-gear\ncodicon-gavel\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "icons.yaml")
+	if err := os.WriteFile(path, []byte("icons: codicons.txt\nexact:\n  lawyer: codicon-gavel\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := LoadIconRules(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	is := NewIconSelector()
+	if issues, err := is.ApplyRules(rules, path); err != nil || len(issues) != 0 {
+		t.Fatalf("Expected the refreshed list to know codicon-gavel, got %v %+v", err, issues)
+	}
+	if icon := is.SelectIcon("lawyer", "", ""); icon != "codicon-gavel" {
+		t.Errorf("Expected codicon-gavel, got %s", icon)
+	}
+}
+
+func TestParseCodicons(t *testing.T) {
+	cases := map[string]string{
+		"codicon.csv":  "short_name,character,unicode\nadd,,EA60\nplus,,EA60\ngavel,,EB33\n",
+		"mapping.json": `{"60000": ["add", "plus"], "60211": ["gavel"]}`,
+		"legacy.json":  `{"add": 60000, "plus": 60000, "gavel": 60211}`,
+		"codicon.css":  ".codicon-add:before { content: \"\\ea60\" }\n.codicon-plus:before { content: \"\\ea60\" }\n.codicon-gavel:before { content: \"\\eb33\" }\n",
+	}
+	for file, data := range cases {
+		icons, err := parseCodicons(file, []byte(data))
+		if err != nil {
+			t.Errorf("%s: %v", file, err)
+			continue
+		}
+		if got := strings.Join(icons, " "); got != "codicon-add codicon-gavel codicon-plus" {
+			t.Errorf("%s: got %s", file, got)
+		}
+	}
+
+	if _, err := parseCodicons("codicon.ttf", nil); err == nil {
+		t.Error("Expected an unsupported file to be rejected")
+	}
+}
+
+func TestConvertDirectory_ReportsUnknownIcons(t *testing.T) {
+	input := t.TempDir()
+	agent := "---\nname: planner\ndescription: Plans work\n---\nYou plan.\n"
+	if err := os.WriteFile(filepath.Join(input, "planner.md"), []byte(agent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewConverter()
+	issues, err := c.iconSelector.ApplyRules(&IconRules{Exact: map[string]string{"planner": "codicon-nope"}}, "icons.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.iconIssues = issues
+	if err := c.convertDirectory(input, t.TempDir(), false, false); err != nil {
+		t.Fatal(err)
+	}
+	report, err := os.ReadFile(filepath.Join(input, "conversion-diagnostic-report.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(report), "### Unknown Icon (1 files)") || !strings.Contains(string(report), "codicon-nope is not a known codicon") {
+		t.Errorf("Expected an unknown icon warning in the report:\n%s", report)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// keyword is a compiled keyword or phrase: the stems of its words, matched as a consecutive run
+type keyword []string
+
+// tokenize splits text into lowercase stemmed words. Anything other than a letter or
+// digit separates words, so "ai" no longer matches inside "maintain" and
+// "react-native" is the phrase "react native".
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = stem(word)
+	}
+	return words
+}
+
+// stem reduces a word to a crude common form so "tests", "testing" and "tested" all
+// match "test". It only has to be consistent, since keywords are stemmed the same way.
+func stem(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		word = word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "sses") || strings.HasSuffix(word, "xes") ||
+		strings.HasSuffix(word, "ches") || strings.HasSuffix(word, "shes"):
+		word = word[:len(word)-2]
+	case strings.HasSuffix(word, "s") && len(word) > 3 &&
+		!strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us") &&
+		(len(word) == 4 || !strings.HasSuffix(word, "is")): // "apis" but not "analysis"
+		word = word[:len(word)-1]
+	}
+
+	trimmed := false
+	switch {
+	case strings.HasSuffix(word, "ing") && len(word) > 6:
+		word, trimmed = word[:len(word)-3], true
+	case strings.HasSuffix(word, "ed") && len(word) > 5:
+		word, trimmed = word[:len(word)-2], true
+	}
+	// "embedding" → "embedd" → "embed"
+	if n := len(word); trimmed && n > 3 && word[n-1] == word[n-2] && !strings.ContainsRune("aeiouslz", rune(word[n-1])) {
+		word = word[:n-1]
+	}
+
+	if strings.HasSuffix(word, "e") && len(word) > 4 {
+		word = word[:len(word)-1]
+	}
+	return word
+}
+
+// compileKeyword tokenizes a keyword or phrase for matching
+func compileKeyword(text string) keyword {
+	return keyword(tokenize(text))
+}
+
+// countIn counts the occurrences of the keyword as a whole-word run in tokens
+func (k keyword) countIn(tokens []string) int {
+	return len(k.indexesIn(tokens))
+}
+
+// indexesIn returns where each whole-word run of the keyword starts in tokens
+func (k keyword) indexesIn(tokens []string) []int {
+	if len(k) == 0 {
+		return nil
+	}
+	var indexes []int
+	for i := 0; i+len(k) <= len(tokens); i++ {
+		matched := true
+		for j, word := range k {
+			if tokens[i+j] != word {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// sortedKeys returns a map's keys in order, so ties between equal scores resolve the same way every run
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	got := strings.Join(tokenize("Tests, testing & tested React-Native APIs!"), " ")
+	if got != "test test test react nativ api" {
+		t.Errorf("Unexpected tokens %q", got)
+	}
+}
+
+func TestStem(t *testing.T) {
+	cases := map[string]string{
+		"reviews":    "review",
+		"libraries":  "library",
+		"processes":  "process",
+		"embedding":  "embed",
+		"automates":  "automat",
+		"automated":  "automat",
+		"analysis":   "analysis",
+		"aws":        "aws",
+		"ios":        "ios",
+		"apis":       "api",
+		"kubernetes": "kubernet",
+	}
+	for word, want := range cases {
+		if got := stem(word); got != want {
+			t.Errorf("stem(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestKeyword_CountIn(t *testing.T) {
+	tokens := tokenize("maintain the build; use react native for mobile, not react alone")
+	cases := map[string]int{
+		"ai":           0, // not inside "maintain"
+		"ui":           0, // not inside "build"
+		"react":        2,
+		"react-native": 1,
+		"mobile":       1,
+	}
+	for word, want := range cases {
+		if got := compileKeyword(word).countIn(tokens); got != want {
+			t.Errorf("%q: got %d matches, want %d", word, got, want)
+		}
+	}
+}
+
+// TestDetermineGroups_WordBoundaries pins capability evidence that must match whole words only
+func TestDetermineGroups_WordBoundaries(t *testing.T) {
+	c := NewConverter()
+	cases := []struct{ name, description, want string }{
+		{"digital-strategist", "Plans digital campaigns", "read"},       // "git" in "digital"
+		{"compiler-historian", "Writes about compiler history", "read"}, // "compile" in "compiler"
+		{"bash-scripter", "Writes bash scripts", "read, edit, command"}, // "write … script" and "bash"
+		{"script-reviewer", "Reviews deployment scripts", "read"},       // objects without a verb
+		{"test-runner", "Runs the test suite", "read, command"},
+	}
+	for _, tc := range cases {
+		got, _ := c.determineGroups(newAnalysisText(tc.name, tc.description, ""), nil)
+		if strings.Join(got, ", ") != tc.want {
+			t.Errorf("%s: got %v, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+// TestClassificationCorpus converts each agent in testdata/classification and compares
+// its icon, groups, description and whenToUse with the matching .golden file
+func TestClassificationCorpus(t *testing.T) {
+	samples, _ := filepath.Glob(filepath.Join("testdata", "classification", "*.md"))
+	if len(samples) == 0 {
+		t.Fatal("No samples found")
+	}
+
+	c := NewConverter()
+	for _, sample := range samples {
+		modes, _, err := c.convertSource(sample)
+		if err != nil {
+			t.Fatalf("%s: %v", sample, err)
+		}
+		mode := modes[0]
+		got := fmt.Sprintf("icon: %s\ngroups: %s\ndescription: %s\nwhenToUse: %s\n",
+			mode.IconName, strings.Join(mode.Groups, ", "), mode.Description, mode.WhenToUse)
+
+		golden := strings.TrimSuffix(sample, ".md") + ".golden"
+		if *updateGolden {
+			if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatalf("%s: %v (run go test -update to create it)", sample, err)
+		}
+		if got != string(want) {
+			t.Errorf("%s:\ngot:\n%s\nwant:\n%s", sample, got, want)
+		}
+	}
+}
@@ -9,17 +9,56 @@ import (
 )
 
 func main() {
+	// Subcommands come before any flags
+	if len(os.Args) > 1 && (os.Args[1] == "train" || os.Args[1] == "icons") {
+		run := runTrain
+		if os.Args[1] == "icons" {
+			run = runIcons
+		}
+		if err := run(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var (
-		input      = flag.String("input", "", "Input file (.md) or directory containing Claude Code sub-agent files")
+		input      = flag.String("input", "", "Input file or directory of agent files (Claude .md, Copilot .chatmode.md, Cursor .mdc, Roo .roomodes)")
 		output     = flag.String("output", "./kilo-modes", "Output directory for Kilo Code mode files")
 		dryRun     = flag.Bool("dry-run", false, "Show what would be converted without creating files")
-		singleFile = flag.Bool("single-files", false, "Output each mode to individual YAML files instead of custom_modes.yaml (directory mode only)")
+		singleFile = flag.Bool("single-files", false, "Output each mode to individual files instead of custom_modes.yaml (directory mode only)")
+		format     = flag.String("format", "yaml", "Output format: yaml or json (Kilo's JSON custom modes form)")
+		strict     = flag.Bool("strict", false, "Fail on any lossy conversion or heuristic fallback (dropped tools, model or file restrictions, sanitized fields, default icon/description/whenToUse)")
+		extras     = flag.String("claude-extras", "none", "Keep Claude frontmatter without a Kilo equivalent (color, model, custom keys): none, inline (x-claude block) or sidecar (<slug>.claude file)")
+		examples   = flag.String("examples", "when-to-use", "Where <example> blocks from descriptions go: when-to-use (condensed triggers) or instructions (an Examples section in customInstructions)")
+		split      = flag.Bool("split-sections", false, "Split prompt bodies by markdown heading: persona sections to roleDefinition, procedures to customInstructions, reference sections to .kilocode/rules-<slug>/ files")
+		splitRules = flag.String("split-rules", "", "YAML file with section routing rules (implies -split-sections)")
+		asRules    = flag.Bool("instructions-as-rules", false, "Write each mode's customInstructions to .kilocode/rules-<slug>/instructions.md instead of the mode file")
+		showDiff   = flag.Bool("show-sanitization", false, "Print a unified diff of every frontmatter rewritten by YAML sanitization")
+		classify   = flag.Bool("classifier", false, "Choose icon, groups and description with the embedded TF-IDF classifier, falling back to keyword rules when no category is close")
+		model      = flag.String("classifier-model", "", "Classifier model written by the train subcommand (implies -classifier)")
+		slugFlag   = flag.String("slug", "", "Slug strategy: name, filename, path (relative to -input) or prefix:<namespace>; overrides .claude2kilo.yaml (default name)")
+		slugMax    = flag.Int("slug-max-length", 0, "Longest slug allowed; longer slugs are shortened and end in a hash; overrides .claude2kilo.yaml (default 64)")
+		acronyms   = flag.String("acronyms", "", "Comma-separated acronyms added to the built-in dictionary for display names, in the casing to use, e.g. FinOps,GCP")
+		nameEmoji  = flag.Bool("name-emoji", false, "Prefix display names with an emoji for their icon's category")
+		catalog    = flag.String("catalog", "", "Also write a browsable catalog of the converted modes to <output>/catalog: html, markdown or html,markdown")
+		policyFile = flag.String("policy", "", "YAML policy file with guardrails (deny, downgrade or warn) applied to every converted mode")
+		maxGroups  = flag.String("max-groups", "", "Comma-separated groups any converted mode may receive, e.g. read,edit (removed groups are reported and refused by -strict)")
+		unique     = flag.Bool("unique-icons", false, "Give each mode in the converted set its own icon, moving weaker matches to their next-best scoring icon")
+		iconRules  = flag.String("icon-rules", "", "YAML file with custom icon rules: exact role names, name regexes, weighted keywords and an optional codicon list")
+		enrich     = flag.String("enrich", "", "Base URL of an OpenAI-compatible endpoint (llama.cpp, Ollama) that repairs unparseable frontmatter and writes roleDefinition, whenToUse and description")
+		enrichLLM  = flag.String("enrich-model", "llama3", "Model name sent to the -enrich endpoint")
+		enrichKey  = flag.String("enrich-api-key", "", "Bearer token sent to the -enrich endpoint (default $"+enrichAPIKeyEnv+")")
+		enrichDir  = flag.String("enrich-cache", defaultEnrichCacheDir(), "Directory caching -enrich answers by request hash (empty disables the cache)")
+		explain    = flag.String("explain", "", "Print why each agent in the given file got its icon, groups, description and whenToUse, without converting")
 		help       = flag.Bool("help", false, "Show help message")
 	)
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Claude Code Sub-agent to Kilo Code Mode Converter\n\n")
-		fmt.Fprintf(os.Stderr, "Usage: %s [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s [options]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s train [-corpus dir] [-output file]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s icons update --from <codicon.csv|mapping.json|codicon.css> [-output file]\n\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		flag.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\nExamples:\n")
@@ -29,6 +68,21 @@ func main() {
 		fmt.Fprintf(os.Stderr, "  %s -input . -output ./kilo-modes/\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "\n  # Convert all files to individual YAML files\n")
 		fmt.Fprintf(os.Stderr, "  %s -input ./claude-agents/ -output ./kilo-modes/ -single-files\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\n  # Emit Kilo's JSON custom modes form\n")
+		fmt.Fprintf(os.Stderr, "  %s -input ./claude-agents/ -output ./kilo-modes/ -format json\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\n  # Import Roo, Copilot or Cursor modes (format is auto-detected)\n")
+		fmt.Fprintf(os.Stderr, "  %s -input .roomodes -output ./kilo-modes/\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\n  # Keep instructions in per-mode rule files next to the modes\n")
+		fmt.Fprintf(os.Stderr, "  %s -input ./claude-agents/ -output . -instructions-as-rules\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\n  # Review what YAML sanitization rewrote\n")
+		fmt.Fprintf(os.Stderr, "  %s -input ./claude-agents/ -output ./kilo-modes/ -dry-run -show-sanitization\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\n  # Classify agents against a model trained on your own labeled corpus\n")
+		fmt.Fprintf(os.Stderr, "  %s train -corpus ./training -output model.json\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -input ./claude-agents/ -output ./kilo-modes/ -classifier-model model.json\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\n  # Let a local Ollama model write descriptions and fix broken frontmatter\n")
+		fmt.Fprintf(os.Stderr, "  %s -input ./claude-agents/ -output ./kilo-modes/ -enrich http://localhost:11434/v1\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\n  # See which keywords chose an agent's icon and groups\n")
+		fmt.Fprintf(os.Stderr, "  %s -explain ./claude-agents/ml-engineer.md\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "\n  # Dry run to see what would be converted\n")
 		fmt.Fprintf(os.Stderr, "  %s -input ./claude-agents/ -output ./converted-modes/ -dry-run\n", os.Args[0])
 	}
@@ -40,13 +94,122 @@ func main() {
 		os.Exit(0)
 	}
 
-	if *input == "" {
+	if *input == "" && *explain == "" {
 		flag.Usage()
 		fmt.Fprintf(os.Stderr, "\nError: input is required\n")
 		os.Exit(1)
 	}
 
+	if *format != "yaml" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: format must be yaml or json, got %q\n", *format)
+		os.Exit(1)
+	}
+
+	if *extras != "none" && *extras != "inline" && *extras != "sidecar" {
+		fmt.Fprintf(os.Stderr, "Error: claude-extras must be none, inline or sidecar, got %q\n", *extras)
+		os.Exit(1)
+	}
+
+	if *examples != "when-to-use" && *examples != "instructions" {
+		fmt.Fprintf(os.Stderr, "Error: examples must be when-to-use or instructions, got %q\n", *examples)
+		os.Exit(1)
+	}
+
 	converter := NewConverter()
+	converter.outputFormat = *format
+	converter.claudeExtras = *extras
+	converter.examples = *examples
+	converter.strict = *strict
+	converter.showSanitization = *showDiff
+	converter.instructionsAsRules = *asRules
+	converter.uniqueIcons = *unique
+
+	if *split || *splitRules != "" {
+		var config *SplitConfig
+		var err error
+		if *splitRules != "" {
+			if config, err = LoadSplitConfig(*splitRules); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if converter.splitter, err = NewSectionSplitter(config); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: split rules: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *classify || *model != "" {
+		var err error
+		if converter.classifier, err = LoadClassifierModel(*model); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	slugOptions := SlugOptions{Strategy: *slugFlag, MaxLength: *slugMax}
+	if err := slugOptions.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	converter.slugStrategy, converter.slugMaxLength = slugOptions.Strategy, slugOptions.MaxLength
+
+	converter.nameOptions = NameOptions{Acronyms: strings.Split(*acronyms, ","), Emoji: *nameEmoji}
+
+	formats, err := ParseCatalogFormats(*catalog)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	converter.catalog = formats
+
+	if *policyFile != "" {
+		var err error
+		if converter.policy, err = LoadPolicy(*policyFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *maxGroups != "" {
+		groups, err := ParseGroupList(*maxGroups)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: max-groups: %v\n", err)
+			os.Exit(1)
+		}
+		converter.maxGroups = groups
+	}
+
+	if *iconRules != "" {
+		rules, err := LoadIconRules(*iconRules)
+		if err == nil {
+			converter.iconIssues, err = converter.iconSelector.ApplyRules(rules, *iconRules)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, issue := range converter.iconIssues {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", issue.FilePath, issue.Description)
+		}
+	}
+
+	if *enrich != "" {
+		apiKey := *enrichKey
+		if apiKey == "" {
+			apiKey = os.Getenv(enrichAPIKeyEnv)
+		}
+		converter.enricher = NewOpenAIEnricher(*enrich, *enrichLLM, apiKey, *enrichDir)
+	}
+
+	if *explain != "" {
+		if err := converter.explainFile(*explain, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			fmt.Fprint(os.Stderr, errorSnippet(err))
+			os.Exit(1)
+		}
+		return
+	}
 
 	// Check if input exists
 	inputInfo, err := os.Stat(*input)
@@ -67,29 +230,45 @@ func main() {
 		}
 	} else {
 		// Convert single file
-		if !strings.HasSuffix(strings.ToLower(*input), ".md") {
-			fmt.Fprintf(os.Stderr, "Error: Input file must have .md extension\n")
+		if !isCandidateFile(filepath.Base(*input)) {
+			fmt.Fprintf(os.Stderr, "Error: Unsupported input file %s\n", filepath.Base(*input))
 			os.Exit(1)
 		}
 
 		if *dryRun {
-			mode, err := converter.convertAgent(*input)
+			modes, _, err := converter.convertSource(*input)
+			if len(modes) > 0 {
+				baseName := strings.TrimSuffix(filepath.Base(*input), filepath.Ext(*input))
+				fmt.Printf("Would convert %s to:\n", filepath.Base(*input))
+				for _, mode := range modes {
+					fmt.Printf("  - %s (in %s%s)\n", mode.Slug, baseName, converter.outputExt())
+				}
+			}
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-				os.Exit(1)
+				for _, modeErr := range rejectedModes(err) {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", modeErr)
+					fmt.Fprint(os.Stderr, errorSnippet(modeErr))
+				}
+				if len(modes) == 0 || converter.strict {
+					os.Exit(1)
+				}
 			}
-			baseName := strings.TrimSuffix(filepath.Base(*input), filepath.Ext(*input))
-			fmt.Printf("Would convert %s to:\n", filepath.Base(*input))
-			fmt.Printf("  - %s (in %s.yaml)\n", mode.Slug, baseName)
 		} else {
 			outputFile, err := converter.convertFile(*input, *output)
+			if outputFile != "" {
+				fmt.Printf("✓ Converted %s\n", filepath.Base(*input))
+				fmt.Printf("  → %s\n", outputFile)
+			}
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-				os.Exit(1)
+				for _, modeErr := range rejectedModes(err) {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", modeErr)
+					fmt.Fprint(os.Stderr, errorSnippet(modeErr))
+				}
+				// A multi-mode file keeps the modes that were not rejected
+				if outputFile == "" || converter.strict {
+					os.Exit(1)
+				}
 			}
-
-			fmt.Printf("✓ Converted %s\n", filepath.Base(*input))
-			fmt.Printf("  → %s\n", outputFile)
 		}
 	}
 }
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// RegionKind classifies where a piece of agent text came from
+type RegionKind string
+
+const (
+	RegionName        RegionKind = "name"
+	RegionDescription RegionKind = "description"
+	RegionHeading     RegionKind = "heading"
+	RegionLead        RegionKind = "lead" // First prose paragraph of the body
+	RegionList        RegionKind = "list"
+	RegionBody        RegionKind = "body"
+)
+
+// regionWeights rank regions by how much they say about an agent's purpose
+var regionWeights = map[RegionKind]int{
+	RegionName:        3,
+	RegionDescription: 2,
+	RegionHeading:     2,
+	RegionLead:        2,
+	RegionList:        1,
+	RegionBody:        1,
+}
+
+// TextRegion is a lowercased span of analyzable text with its weight and stemmed words
+type TextRegion struct {
+	Kind   RegionKind
+	Text   string
+	Weight int
+	Tokens []string
+}
+
+// AnalysisText is an agent preprocessed for keyword analysis. Code blocks, inline
+// code, URLs and link targets are removed, so only prose is matched.
+type AnalysisText struct {
+	Name    string // The agent's name as written, for rules that match it verbatim
+	Regions []TextRegion
+}
+
+var (
+	inlineCodeRe  = regexp.MustCompile("`[^`\n]*`")
+	imageLinkRe   = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownURLRe = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	autoLinkRe    = regexp.MustCompile(`<(?:https?|mailto|ftp):[^>\s]*>`)
+	bareURLRe     = regexp.MustCompile(`(?i)\b(?:https?|ftp)://\S+|\bwww\.\S+`)
+	htmlTagRe     = regexp.MustCompile(`</?[A-Za-z][^>]*>`)
+	headingLineRe = regexp.MustCompile(`^#{1,6}\s+(.*?)\s*#*$`)
+	listItemRe    = regexp.MustCompile(`^(?:[-*+]|\d+[.)])\s+(.*)$`)
+)
+
+// newAnalysisText preprocesses an agent's name, description and markdown body into weighted regions
+func newAnalysisText(name, description, content string) *AnalysisText {
+	a := &AnalysisText{Name: name}
+	a.add(RegionName, strings.NewReplacer("-", " ", "_", " ").Replace(name))
+	a.add(RegionDescription, cleanInline(description))
+	for _, region := range preprocessMarkdown(content) {
+		a.add(region.Kind, region.Text)
+	}
+	return a
+}
+
+// plainAnalysisText wraps already-flattened text as a single body region
+func plainAnalysisText(text string) *AnalysisText {
+	a := &AnalysisText{}
+	a.add(RegionBody, text)
+	return a
+}
+
+// add appends a region, skipping empty text
+func (a *AnalysisText) add(kind RegionKind, text string) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text != "" {
+		a.Regions = append(a.Regions, TextRegion{Kind: kind, Text: text, Weight: regionWeights[kind], Tokens: tokenize(text)})
+	}
+}
+
+// Text returns every region joined, for analyzers that only need to know whether a term occurs
+func (a *AnalysisText) Text() string {
+	parts := make([]string, len(a.Regions))
+	for i, region := range a.Regions {
+		parts[i] = region.Text
+	}
+	return strings.Join(parts, " ")
+}
+
+// Kind returns the text of all regions of one kind
+func (a *AnalysisText) Kind(kind RegionKind) string {
+	var parts []string
+	for _, region := range a.Regions {
+		if region.Kind == kind {
+			parts = append(parts, region.Text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// matches lists the regions in which a keyword or phrase occurs as whole words
+func (a *AnalysisText) matches(text string) []KeywordMatch {
+	k := compileKeyword(text)
+	var found []KeywordMatch
+	for _, region := range a.Regions {
+		if count := k.countIn(region.Tokens); count > 0 {
+			found = append(found, KeywordMatch{Keyword: text, Region: region.Kind, Count: count, Weight: region.Weight})
+		}
+	}
+	return found
+}
+
+// score sums the weighted whole-word occurrences of a keyword or phrase across all regions
+func (a *AnalysisText) score(text string) int {
+	total := 0
+	for _, match := range a.matches(text) {
+		total += match.Weight * match.Count
+	}
+	return total
+}
+
+// contains reports whether a keyword or phrase occurs as whole words in any region
+func (a *AnalysisText) contains(text string) bool {
+	return a.score(text) > 0
+}
+
+// containsAny reports whether any of the keywords occurs in any region
+func (a *AnalysisText) containsAny(keywords ...string) bool {
+	for _, k := range keywords {
+		if a.contains(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesIn lists the matches of a keyword or phrase in regions of the given kinds
+func (a *AnalysisText) matchesIn(text string, kinds ...RegionKind) []KeywordMatch {
+	var found []KeywordMatch
+	for _, match := range a.matches(text) {
+		for _, kind := range kinds {
+			if match.Region == kind {
+				found = append(found, match)
+			}
+		}
+	}
+	return found
+}
+
+// containsIn reports whether a keyword or phrase occurs in a region of one of the given kinds
+func (a *AnalysisText) containsIn(text string, kinds ...RegionKind) bool {
+	return len(a.matchesIn(text, kinds...)) > 0
+}
+
+// preprocessMarkdown splits a markdown body into heading, lead, list and body regions,
+// dropping fenced code blocks, inline code, URLs and link targets
+func preprocessMarkdown(markdown string) []TextRegion {
+	var regions []TextRegion
+	var paragraph []string
+	leadSeen := false
+	fence := ""
+
+	flush := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		kind := RegionBody
+		if !leadSeen {
+			kind, leadSeen = RegionLead, true
+		}
+		regions = append(regions, TextRegion{Kind: kind, Text: strings.Join(paragraph, " ")})
+		paragraph = nil
+	}
+
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		// Fenced code blocks end only at a fence of the same kind
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			flush()
+			fence = trimmed[:3]
+			continue
+		}
+
+		switch match := headingLineRe.FindStringSubmatch(trimmed); {
+		case trimmed == "":
+			flush()
+		case match != nil:
+			flush()
+			regions = append(regions, TextRegion{Kind: RegionHeading, Text: cleanInline(match[1])})
+		case listItemRe.MatchString(trimmed):
+			flush()
+			item := listItemRe.FindStringSubmatch(trimmed)[1]
+			regions = append(regions, TextRegion{Kind: RegionList, Text: cleanInline(item)})
+		default:
+			paragraph = append(paragraph, cleanInline(strings.Trim(trimmed, "|>")))
+		}
+	}
+	flush()
+
+	var kept []TextRegion
+	for _, region := range regions {
+		if region.Text = strings.TrimSpace(region.Text); region.Text != "" {
+			region.Weight = regionWeights[region.Kind]
+			kept = append(kept, region)
+		}
+	}
+	return kept
+}
+
+// cleanInline removes inline code, URLs and HTML tags, keeping link and image text
+func cleanInline(text string) string {
+	text = inlineCodeRe.ReplaceAllString(text, " ")
+	text = imageLinkRe.ReplaceAllString(text, "$1")
+	text = markdownURLRe.ReplaceAllString(text, "$1")
+	text = autoLinkRe.ReplaceAllString(text, " ")
+	text = bareURLRe.ReplaceAllString(text, " ")
+	text = htmlTagRe.ReplaceAllString(text, " ")
+	return collapseWhitespace(text)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const uxWriterBody = `You are a UX writer who crafts microcopy for onboarding flows.
+
+## Voice
+
+- Friendly, concise and human
+- See the [style guide](https://docs.example.com/backend/api/server) before writing
+
+Document error states with the actual endpoint, for example ` + "`GET /api/v1/users`" + `.
+
+` + "```bash" + `
+curl https://api.example.com/users | jq '.database.server'
+# backend api server database microservice graphql rest
+` + "```" + `
+`
+
+func TestPreprocessMarkdown_Regions(t *testing.T) {
+	regions := preprocessMarkdown(uxWriterBody)
+
+	want := []RegionKind{RegionLead, RegionHeading, RegionList, RegionList, RegionBody}
+	if len(regions) != len(want) {
+		t.Fatalf("Expected %d regions, got %+v", len(want), regions)
+	}
+	for i, kind := range want {
+		if regions[i].Kind != kind {
+			t.Errorf("Region %d: expected %s, got %s (%q)", i, kind, regions[i].Kind, regions[i].Text)
+		}
+	}
+	if regions[3].Text != "See the style guide before writing" {
+		t.Errorf("Expected link text without its target, got %q", regions[3].Text)
+	}
+}
+
+func TestPreprocessMarkdown_DropsCodeAndURLs(t *testing.T) {
+	text := newAnalysisText("ux-writer", "Writes product copy. See <https://example.com/api>.", uxWriterBody).Text()
+	for _, excluded := range []string{"curl", "api", "database", "graphql", "example.com", "/users"} {
+		if strings.Contains(text, excluded) {
+			t.Errorf("Expected %q to be excluded from analysis text: %q", excluded, text)
+		}
+	}
+	for _, kept := range []string{"ux writer", "microcopy", "style guide"} {
+		if !strings.Contains(text, kept) {
+			t.Errorf("Expected %q in analysis text: %q", kept, text)
+		}
+	}
+}
+
+func TestAnalysisText_Score(t *testing.T) {
+	a := newAnalysisText("review-bot", "", "# Review checklist\n\nreviews once")
+	// name (3) + heading (2) + lead (2)
+	if got := a.score("review"); got != 7 {
+		t.Errorf("Expected weighted score 7, got %d", got)
+	}
+}
+
+func TestBuildMode_CodeSamplesDoNotSteerHeuristics(t *testing.T) {
+	c := NewConverter()
+	mode := c.buildMode(&SourceAgent{Name: "ux-writer", Description: "Writes onboarding microcopy", Body: uxWriterBody, Format: "claude"})
+
+	for _, group := range mode.Groups {
+		if group == "command" {
+			t.Errorf("Expected the bash sample not to grant command, got %v", mode.Groups)
+		}
+	}
+	if mode.Description == "Backend development" {
+		t.Errorf("Expected a non-backend description, got %q", mode.Description)
+	}
+	if mode.IconName == "codicon-server" {
+		t.Errorf("Expected a non-backend icon, got %q", mode.IconName)
+	}
+	if strings.Contains(mode.WhenToUse, "backend") {
+		t.Errorf("Expected whenToUse without backend, got %q", mode.WhenToUse)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+)
+
+// editOptions restrict the edit group to matching files, as in ["edit", {fileRegex: ...}]
+type editOptions struct {
+	FileRegex   string `yaml:"fileRegex" json:"fileRegex"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+}
+
+// kiloModeOutput is a KiloMode as Kilo reads it, with group entries that may carry
+// options. It is the only place the serialized field names are declared.
+type kiloModeOutput struct {
+	Slug               string                 `yaml:"slug" json:"slug"`
+	Name               string                 `yaml:"name" json:"name"`
+	IconName           string                 `yaml:"iconName" json:"iconName"`
+	RoleDefinition     string                 `yaml:"roleDefinition" json:"roleDefinition"`
+	WhenToUse          string                 `yaml:"whenToUse,omitempty" json:"whenToUse,omitempty"`
+	Description        string                 `yaml:"description" json:"description"`
+	Groups             []interface{}          `yaml:"groups" json:"groups"`
+	CustomInstructions string                 `yaml:"customInstructions,omitempty" json:"customInstructions,omitempty"`
+	Source             string                 `yaml:"source" json:"source"`
+	XClaude            map[string]interface{} `yaml:"x-claude,omitempty" json:"x-claude,omitempty"`
+}
+
+// output converts a mode to the form written to mode files
+func (m KiloMode) output() kiloModeOutput {
+	groups := make([]interface{}, 0, len(m.Groups))
+	for _, group := range m.Groups {
+		if group == "edit" && m.FileRegex != "" {
+			groups = append(groups, []interface{}{group, editOptions{FileRegex: m.FileRegex, Description: m.FileRegexDescription}})
+		} else {
+			groups = append(groups, group)
+		}
+	}
+	return kiloModeOutput{
+		Slug: m.Slug, Name: m.Name, IconName: m.IconName, RoleDefinition: m.RoleDefinition, WhenToUse: m.WhenToUse,
+		Description: m.Description, Groups: groups, CustomInstructions: m.CustomInstructions, Source: m.Source, XClaude: m.XClaude,
+	}
+}
+
+// apply copies a decoded mode file entry onto the mode
+func (o kiloModeOutput) apply(m *KiloMode) {
+	m.Slug, m.Name, m.IconName, m.RoleDefinition, m.WhenToUse = o.Slug, o.Name, o.IconName, o.RoleDefinition, o.WhenToUse
+	m.Description, m.CustomInstructions, m.Source, m.XClaude = o.Description, o.CustomInstructions, o.Source, o.XClaude
+	m.Groups, m.FileRegex, m.FileRegexDescription = flattenGroups(o.Groups)
+}
+
+// restrictsEdit reports whether the mode as written has an edit group limited by a fileRegex
+func (m KiloMode) restrictsEdit() bool {
+	for _, group := range m.output().Groups {
+		if entry, ok := group.([]interface{}); ok && entry[0] == "edit" {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalYAML writes the edit group with its file restriction
+func (m KiloMode) MarshalYAML() (interface{}, error) {
+	return m.output(), nil
+}
+
+// UnmarshalYAML reads group entries written with options
+func (m *KiloMode) UnmarshalYAML(node *yaml.Node) error {
+	var out kiloModeOutput
+	if err := node.Decode(&out); err != nil {
+		return err
+	}
+	out.apply(m)
+	return nil
+}
+
+// MarshalJSON writes the edit group with its file restriction, leaving HTML characters readable
+func (m KiloMode) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(m.output()); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// UnmarshalJSON reads group entries written with options
+func (m *KiloMode) UnmarshalJSON(data []byte) error {
+	var out kiloModeOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	out.apply(m)
+	return nil
+}
+
+// flattenGroups reads group entries, which are either a name or a [name, options] pair,
+// and returns the names and any file restriction
+func flattenGroups(entries []interface{}) (groups []string, fileRegex, description string) {
+	for _, entry := range entries {
+		switch group := entry.(type) {
+		case string:
+			groups = append(groups, group)
+		case []interface{}:
+			if len(group) == 0 {
+				continue
+			}
+			name, ok := group[0].(string)
+			if !ok {
+				continue
+			}
+			groups = append(groups, name)
+			if len(group) > 1 {
+				if options, ok := group[1].(map[string]interface{}); ok {
+					fileRegex, _ = options["fileRegex"].(string)
+					description, _ = options["description"].(string)
+				}
+			}
+		}
+	}
+	return groups, fileRegex, description
+}
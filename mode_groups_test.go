@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// internalModeFields are the KiloMode fields that are conversion state, not written to mode files
+var internalModeFields = map[string]bool{
+	"FileRegex": true, "FileRegexDescription": true, "OriginalModel": true, "Plugin": true, "SourceFile": true,
+	"ClaudeExtras": true, "Losses": true, "Examples": true, "RuleFiles": true, "Traces": true,
+	"IconCandidates": true, "Violations": true,
+}
+
+// TestKiloModeOutput_RoundTrip fails when a field is added to KiloMode or kiloModeOutput
+// without the other, or without being copied by output and apply
+func TestKiloModeOutput_RoundTrip(t *testing.T) {
+	modeType := reflect.TypeOf(KiloMode{})
+	outputType := reflect.TypeOf(kiloModeOutput{})
+	for i := 0; i < modeType.NumField(); i++ {
+		name := modeType.Field(i).Name
+		if _, written := outputType.FieldByName(name); written == internalModeFields[name] {
+			t.Errorf("KiloMode.%s must be either declared in kiloModeOutput or listed as internal", name)
+		}
+	}
+	for i := 0; i < outputType.NumField(); i++ {
+		if _, ok := modeType.FieldByName(outputType.Field(i).Name); !ok {
+			t.Errorf("kiloModeOutput.%s has no KiloMode field", outputType.Field(i).Name)
+		}
+	}
+
+	// Every written field gets a distinct value, so a field output or apply skips comes back empty
+	var mode KiloMode
+	value := reflect.ValueOf(&mode).Elem()
+	for i := 0; i < modeType.NumField(); i++ {
+		field := modeType.Field(i)
+		if internalModeFields[field.Name] {
+			continue
+		}
+		switch field.Type.Kind() {
+		case reflect.String:
+			value.Field(i).SetString(field.Name + " value")
+		case reflect.Slice:
+			value.Field(i).Set(reflect.ValueOf([]string{"read", "edit"}))
+		case reflect.Map:
+			value.Field(i).Set(reflect.ValueOf(map[string]interface{}{"model": field.Name}))
+		default:
+			t.Fatalf("No test value for KiloMode.%s of kind %s", field.Name, field.Type.Kind())
+		}
+	}
+	mode.FileRegex, mode.FileRegexDescription = `\.md$`, "Markdown only"
+
+	formats := map[string]struct {
+		marshal   func(interface{}) ([]byte, error)
+		unmarshal func([]byte, interface{}) error
+	}{
+		"yaml": {yaml.Marshal, yaml.Unmarshal},
+		"json": {json.Marshal, json.Unmarshal},
+	}
+	for name, format := range formats {
+		data, err := format.marshal(mode)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var decoded KiloMode
+		if err := format.unmarshal(data, &decoded); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got, want := fmt.Sprint(decoded), fmt.Sprint(mode); got != want {
+			t.Errorf("%s round trip lost fields:\nwant %s\ngot  %s\n%s", name, want, got, data)
+		}
+	}
+}
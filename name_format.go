@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// defaultAcronyms are spelled this way in display names whatever the source's casing
+var defaultAcronyms = []string{
+	"AI", "ML", "LLM", "RAG", "NLP", "API", "SDK", "CLI", "UI", "UX", "QA", "CI", "CD", "SRE", "DBA", "SEO",
+	"SQL", "NoSQL", "PostgreSQL", "MySQL", "MongoDB", "GraphQL", "gRPC", "REST", "HTTP", "JSON", "YAML", "HTML",
+	"CSS", "JS", "TS", "JavaScript", "TypeScript", "PHP", "iOS", "macOS", "DevOps", "DevSecOps", "MLOps",
+	"GitOps", "GitHub", "GitLab", "AWS", "GCP", "ETL", "OAuth", "WordPress", "CTO", "B2B", "SaaS",
+}
+
+// nameMinorWords stay lowercase inside a display name ("Head of Sales")
+var nameMinorWords = map[string]bool{
+	"a": true, "an": true, "and": true, "as": true, "at": true, "by": true, "for": true, "in": true,
+	"of": true, "on": true, "or": true, "the": true, "to": true, "vs": true, "with": true,
+}
+
+// NameOptions configures display names, from flags or a .claude2kilo.yaml names section
+type NameOptions struct {
+	Acronyms []string `yaml:"acronyms"` // Added to defaultAcronyms, in the casing to use
+	Emoji    bool     `yaml:"emoji"`    // Prefix names with an emoji for their icon's category
+}
+
+// NameFormatter turns agent names such as "ai-engineer" into display names such as "AI Engineer"
+type NameFormatter struct {
+	acronyms map[string]string // Lowercase word to its spelling
+	emoji    bool
+}
+
+// NewNameFormatter creates a formatter with the default acronyms plus any extra ones
+func NewNameFormatter(options NameOptions) *NameFormatter {
+	f := &NameFormatter{acronyms: make(map[string]string), emoji: options.Emoji}
+	for _, acronym := range append(append([]string{}, defaultAcronyms...), options.Acronyms...) {
+		if acronym = strings.TrimSpace(acronym); acronym != "" {
+			f.acronyms[strings.ToLower(acronym)] = acronym
+		}
+	}
+	return f
+}
+
+// nameFormatterFor returns the formatter for a source file: the flags plus the
+// nearest .claude2kilo.yaml with a names section
+func (c *Converter) nameFormatterFor(sourcePath string) *NameFormatter {
+	options := c.nameOptions
+	if config := c.nearestConfig(sourcePath, func(config *sourceConfig) bool { return config.Names != nil }); config != nil {
+		options.Acronyms = append(append([]string{}, config.Names.Acronyms...), options.Acronyms...)
+		options.Emoji = options.Emoji || config.Names.Emoji
+	}
+	return NewNameFormatter(options)
+}
+
+// Format splits a name on hyphens, underscores and spaces and capitalizes each word.
+// Known acronyms get their dictionary spelling, and words already written in mixed
+// case ("iOS", "McAfee") are kept, unless the whole name is shouted in capitals.
+func (f *NameFormatter) Format(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '_' || unicode.IsSpace(r) })
+	shouted := strings.ToUpper(name) == name
+	for i, word := range words {
+		lower := strings.ToLower(word)
+		switch {
+		case f.acronyms[lower] != "":
+			words[i] = f.acronyms[lower]
+		case i > 0 && nameMinorWords[lower]:
+			words[i] = lower
+		case !shouted && strings.ToLower(word) != word:
+			// Already capitalized by the author
+		default:
+			runes := []rune(lower)
+			words[i] = string(unicode.ToUpper(runes[0])) + string(runes[1:])
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// iconEmoji groups the icons the selector chooses into categories with one emoji each
+var iconEmoji = map[string][]string{
+	"🤖":  {"codicon-robot", "codicon-copilot", "codicon-circuit-board", "codicon-wand", "codicon-lightbulb", "codicon-lightbulb-autofix", "codicon-symbol-array"},
+	"🧪":  {"codicon-beaker", "codicon-beaker-stop", "codicon-pass"},
+	"🐛":  {"codicon-bug"},
+	"🛡️": {"codicon-shield", "codicon-verified"},
+	"⚖️": {"codicon-law"},
+	"🗄️": {"codicon-database", "codicon-table"},
+	"📊":  {"codicon-graph-line", "codicon-pie-chart", "codicon-graph-scatter", "codicon-inspect"},
+	"🔀":  {"codicon-arrow-swap", "codicon-arrow-right"},
+	"🎨":  {"codicon-paintcan", "codicon-color-mode"},
+	"🌐":  {"codicon-browser", "codicon-globe"},
+	"📱":  {"codicon-device-mobile"},
+	"🖥️": {"codicon-server", "codicon-server-process", "codicon-plug"},
+	"☁️": {"codicon-cloud", "codicon-azure"},
+	"🚀":  {"codicon-rocket", "codicon-run-all"},
+	"⚙️": {"codicon-gear", "codicon-tools", "codicon-package"},
+	"🏛️": {"codicon-type-hierarchy-sub"},
+	"💻":  {"codicon-code", "codicon-python", "codicon-symbol-method", "codicon-symbol-interface", "codicon-symbol-class", "codicon-symbol-structure"},
+	"🔍":  {"codicon-code-review", "codicon-eye", "codicon-search", "codicon-telescope"},
+	"⚡":  {"codicon-pulse"},
+	"⚠️": {"codicon-warning"},
+	"📡":  {"codicon-broadcast"},
+	"📝":  {"codicon-book"},
+	"📣":  {"codicon-megaphone"},
+	"💼":  {"codicon-briefcase"},
+	"👥":  {"codicon-person", "codicon-organization", "codicon-comment-discussion"},
+	"🎓":  {"codicon-mortar-board"},
+	"⭐":  {"codicon-star-full"},
+}
+
+// defaultIconEmoji prefixes names whose icon has no category
+const defaultIconEmoji = "🧩"
+
+// emojiByIcon inverts iconEmoji
+var emojiByIcon = func() map[string]string {
+	byIcon := make(map[string]string)
+	for emoji, icons := range iconEmoji {
+		for _, icon := range icons {
+			byIcon[icon] = emoji
+		}
+	}
+	return byIcon
+}()
+
+// emojiFor returns the emoji for an icon's category
+func emojiFor(icon string) string {
+	if emoji := emojiByIcon[icon]; emoji != "" {
+		return emoji
+	}
+	if strings.HasPrefix(icon, "codicon-symbol-") {
+		return emojiByIcon["codicon-code"]
+	}
+	return defaultIconEmoji
+}
+
+// withEmoji prefixes a display name with its icon's emoji, unless it already starts with a symbol
+func withEmoji(name, icon string) string {
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return name
+		}
+		break
+	}
+	return fmt.Sprintf("%s %s", emojiFor(icon), name)
+}
+
+// swapEmoji replaces the emoji withEmoji gave a name when the mode's icon changes
+func swapEmoji(name, previousIcon, icon string) string {
+	if rest, ok := strings.CutPrefix(name, emojiFor(previousIcon)+" "); ok {
+		return emojiFor(icon) + " " + rest
+	}
+	return name
+}
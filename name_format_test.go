@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNameFormatter_Format(t *testing.T) {
+	f := NewNameFormatter(NameOptions{Acronyms: []string{"FinOps"}})
+	cases := map[string]string{
+		"ai-engineer":            "AI Engineer",
+		"graphql-architect":      "GraphQL Architect",
+		"iOS Developer":          "iOS Developer",
+		"code_reviewer":          "Code Reviewer",
+		"api-for-payments":       "API for Payments",
+		"devops  troubleshooter": "DevOps Troubleshooter",
+		"McKinsey-style analyst": "McKinsey Style Analyst",
+		"SECURITY AUDITOR":       "Security Auditor",
+		"finops-advisor":         "FinOps Advisor",
+	}
+	for name, want := range cases {
+		if got := f.Format(name); got != want {
+			t.Errorf("Format(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestBuildMode_NameEmoji(t *testing.T) {
+	c := NewConverter()
+	c.nameOptions.Emoji = true
+	mode := c.buildMode(&SourceAgent{Name: "ai-engineer", Description: "Builds LLM applications", Format: "claude"})
+	if mode.Name != "🤖 AI Engineer" {
+		t.Errorf("Expected the AI category emoji, got %q", mode.Name)
+	}
+	if strings.Contains(mode.RoleDefinition, "🤖") {
+		t.Errorf("Expected no emoji in the roleDefinition, got %q", mode.RoleDefinition)
+	}
+
+	// A reassigned icon brings its own emoji; names that already start with one are left alone
+	reassignIcon(mode, "codicon-beaker", "other")
+	if mode.Name != "🧪 AI Engineer" {
+		t.Errorf("Expected the emoji to follow the icon, got %q", mode.Name)
+	}
+	if got := withEmoji("🏗️ Architect", "codicon-gear"); got != "🏗️ Architect" {
+		t.Errorf("Expected an existing emoji to be kept, got %q", got)
+	}
+}
+
+func TestNameFormatterFor_Config(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, sourceConfigName), []byte("names:\n  acronyms: [k8s]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c := NewConverter()
+	c.inputRoot = root
+	f := c.nameFormatterFor(filepath.Join(root, "ops", "k8s-operator.md"))
+	if got := f.Format("k8s-operator"); got != "k8s Operator" {
+		t.Errorf("Expected the configured acronym, got %q", got)
+	}
+}
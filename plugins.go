@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// pluginInfo describes a Claude Code plugin found in the input tree
+type pluginInfo struct {
+	Name        string
+	Root        string
+	Marketplace string
+	AgentPaths  []string
+}
+
+// pluginManifest represents the parts of .claude-plugin/plugin.json we use
+type pluginManifest struct {
+	Name   string      `json:"name"`
+	Agents interface{} `json:"agents"`
+}
+
+// marketplaceManifest represents the parts of .claude-plugin/marketplace.json we use
+type marketplaceManifest struct {
+	Name    string `json:"name"`
+	Plugins []struct {
+		Name   string      `json:"name"`
+		Source interface{} `json:"source"`
+		Agents interface{} `json:"agents"`
+	} `json:"plugins"`
+}
+
+// discoverPlugins finds plugin and marketplace manifests under inputDir
+func discoverPlugins(inputDir string) ([]*pluginInfo, error) {
+	byRoot := make(map[string]*pluginInfo)
+
+	// pluginAt returns the plugin rooted at dir, registering it on first sight
+	pluginAt := func(root string) *pluginInfo {
+		root = filepath.Clean(root)
+		if plugin, ok := byRoot[root]; ok {
+			return plugin
+		}
+		plugin := &pluginInfo{
+			Name:       filepath.Base(root),
+			Root:       root,
+			AgentPaths: []string{filepath.Join(root, "agents")},
+		}
+		byRoot[root] = plugin
+		return plugin
+	}
+
+	err := filepath.WalkDir(inputDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || d.Name() != ".claude-plugin" {
+			return nil
+		}
+		root := filepath.Dir(path)
+
+		if data, err := os.ReadFile(filepath.Join(path, "plugin.json")); err == nil {
+			var manifest pluginManifest
+			if err := json.Unmarshal(data, &manifest); err != nil {
+				return fmt.Errorf("invalid plugin manifest %s: %w", filepath.Join(path, "plugin.json"), err)
+			}
+			plugin := pluginAt(root)
+			if manifest.Name != "" {
+				plugin.Name = manifest.Name
+			}
+			plugin.addAgentPaths(manifest.Agents)
+		}
+
+		if data, err := os.ReadFile(filepath.Join(path, "marketplace.json")); err == nil {
+			var manifest marketplaceManifest
+			if err := json.Unmarshal(data, &manifest); err != nil {
+				return fmt.Errorf("invalid marketplace manifest %s: %w", filepath.Join(path, "marketplace.json"), err)
+			}
+			for _, entry := range manifest.Plugins {
+				// Only local sources can be converted; git and URL sources are skipped
+				source, ok := entry.Source.(string)
+				if !ok || strings.Contains(source, "://") {
+					continue
+				}
+				plugin := pluginAt(filepath.Join(root, source))
+				if entry.Name != "" {
+					plugin.Name = entry.Name
+				}
+				plugin.Marketplace = manifest.Name
+				plugin.addAgentPaths(entry.Agents)
+			}
+		}
+
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	plugins := make([]*pluginInfo, 0, len(byRoot))
+	for _, plugin := range byRoot {
+		plugins = append(plugins, plugin)
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Root < plugins[j].Root })
+	return plugins, nil
+}
+
+// addAgentPaths records custom agent locations, given as a path or a list of paths
+func (p *pluginInfo) addAgentPaths(value interface{}) {
+	var paths []string
+	switch v := value.(type) {
+	case string:
+		paths = append(paths, v)
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				paths = append(paths, s)
+			}
+		}
+	}
+	for _, path := range paths {
+		p.AgentPaths = append(p.AgentPaths, filepath.Join(p.Root, path))
+	}
+}
+
+// ownsAgent reports whether path is one of the plugin's agent files
+func (p *pluginInfo) ownsAgent(path string) bool {
+	for _, agentPath := range p.AgentPaths {
+		if filepath.Clean(path) == agentPath || withinDir(path, agentPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// namespace prefixes a mode's slug with the plugin name so plugins cannot clash
+func (p *pluginInfo) namespace(c *Converter, mode *KiloMode) {
+	mode.Plugin = p.Name
+	mode.Slug = limitSlug(prefixSlug(c.generateSlug(p.Name), mode.Slug), c.slugMaxLength)
+}
+
+// findPlugin returns the innermost plugin containing path, or nil
+func findPlugin(plugins []*pluginInfo, path string) *pluginInfo {
+	var best *pluginInfo
+	for _, plugin := range plugins {
+		if withinDir(path, plugin.Root) {
+			if best == nil || len(plugin.Root) > len(best.Root) {
+				best = plugin
+			}
+		}
+	}
+	return best
+}
+
+// withinDir reports whether path lies below dir
+func withinDir(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// newPluginSummary describes a plugin's bundled components for the report
+func newPluginSummary(plugin *pluginInfo, inputDir string) *PluginSummary {
+	summary := &PluginSummary{
+		Name:        plugin.Name,
+		Marketplace: plugin.Marketplace,
+		Root:        plugin.Root,
+	}
+	if rel, err := filepath.Rel(inputDir, plugin.Root); err == nil {
+		summary.Root = rel
+	}
+
+	if entries, err := os.ReadDir(filepath.Join(plugin.Root, "commands")); err == nil {
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(strings.ToLower(entry.Name()), ".md") {
+				summary.Commands++
+			}
+		}
+	}
+	if _, err := os.Stat(filepath.Join(plugin.Root, "hooks")); err == nil {
+		summary.HasHooks = true
+	}
+	if _, err := os.Stat(filepath.Join(plugin.Root, ".mcp.json")); err == nil {
+		summary.HasMCP = true
+	}
+
+	return summary
+}
+
+// printPluginSummaries prints per-plugin conversion counts
+func printPluginSummaries(summaries []PluginSummary) {
+	if len(summaries) == 0 {
+		return
+	}
+	fmt.Printf("\nPlugins:\n")
+	for _, summary := range summaries {
+		name := summary.Name
+		if summary.Marketplace != "" {
+			name = summary.Marketplace + "/" + summary.Name
+		}
+		fmt.Printf("  %s: %d/%d agents converted", name, summary.Converted, summary.Files)
+		if skipped := summary.skippedComponents(); skipped != "" {
+			fmt.Printf(" (not converted: %s)", skipped)
+		}
+		fmt.Printf("\n")
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const pluginAgent = `---
+name: python-pro
+description: Write idiomatic Python code
+---
+You are a Python expert.`
+
+func writeMarketplace(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeTestFile(t, dir, ".claude-plugin/marketplace.json", `{
+  "name": "community",
+  "plugins": [
+    {"name": "alpha", "source": "./plugins/alpha"},
+    {"name": "remote", "source": {"source": "github", "repo": "x/y"}}
+  ]
+}`)
+	writeTestFile(t, dir, "plugins/alpha/agents/python-pro.md", pluginAgent)
+	writeTestFile(t, dir, "plugins/alpha/commands/lint.md", "---\ndescription: Run the linter\n---\nLint it.")
+	writeTestFile(t, dir, "plugins/beta/.claude-plugin/plugin.json", `{"name": "beta", "agents": "./extra"}`)
+	writeTestFile(t, dir, "plugins/beta/agents/python-pro.md", pluginAgent)
+	writeTestFile(t, dir, "plugins/beta/extra/reviewer.md", "---\nname: reviewer\ndescription: Review code\n---\nReview.")
+	writeTestFile(t, dir, "plugins/beta/.mcp.json", `{"mcpServers": {}}`)
+	return dir
+}
+
+func TestDiscoverPlugins(t *testing.T) {
+	dir := writeMarketplace(t)
+	plugins, err := discoverPlugins(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(plugins) != 2 {
+		t.Fatalf("Expected 2 local plugins, got %d", len(plugins))
+	}
+	if plugins[0].Name != "alpha" || plugins[0].Marketplace != "community" {
+		t.Errorf("Unexpected first plugin: %+v", plugins[0])
+	}
+	if plugins[1].Name != "beta" || len(plugins[1].AgentPaths) != 2 {
+		t.Errorf("Expected beta with custom agent path, got %+v", plugins[1])
+	}
+}
+
+func TestFindPluginAndOwnsAgent(t *testing.T) {
+	dir := writeMarketplace(t)
+	plugins, _ := discoverPlugins(dir)
+
+	agent := filepath.Join(dir, "plugins", "alpha", "agents", "python-pro.md")
+	plugin := findPlugin(plugins, agent)
+	if plugin == nil || plugin.Name != "alpha" || !plugin.ownsAgent(agent) {
+		t.Fatalf("Expected alpha to own %s, got %+v", agent, plugin)
+	}
+
+	command := filepath.Join(dir, "plugins", "alpha", "commands", "lint.md")
+	if plugin.ownsAgent(command) {
+		t.Error("Expected commands not to be treated as agents")
+	}
+	if findPlugin(plugins, filepath.Join(dir, "README.md")) != nil {
+		t.Error("Expected files outside plugins to have no plugin")
+	}
+}
+
+func TestConvertDirectory_Marketplace(t *testing.T) {
+	dir := writeMarketplace(t)
+	out := t.TempDir()
+	c := NewConverter()
+	if err := c.convertDirectory(dir, out, false, false); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(out, "custom_modes.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	for _, slug := range []string{"alpha-python-pro", "beta-python-pro", "beta-reviewer"} {
+		if !strings.Contains(string(data), "slug: "+slug) {
+			t.Errorf("Expected namespaced slug %q in output", slug)
+		}
+	}
+	if strings.Contains(string(data), "lint") {
+		t.Error("Expected plugin commands to be skipped")
+	}
+
+	report, err := os.ReadFile(filepath.Join(dir, "conversion-diagnostic-report.md"))
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	if !strings.Contains(string(report), "## Plugins") || !strings.Contains(string(report), "1 commands") {
+		t.Error("Expected per-plugin section in report")
+	}
+}
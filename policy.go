@@ -0,0 +1,280 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PolicyAction is what a policy rule does to a mode it matches
+type PolicyAction string
+
+const (
+	PolicyDeny      PolicyAction = "deny"      // The source file fails to convert
+	PolicyDowngrade PolicyAction = "downgrade" // The mode keeps only the rule's allowed groups
+	PolicyWarn      PolicyAction = "warn"      // The mode converts unchanged
+)
+
+// globList is one glob or a list of them in the policy file
+type globList []string
+
+// UnmarshalYAML accepts a single glob as well as a list
+func (g *globList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*g = globList{node.Value}
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*g = list
+	return nil
+}
+
+// PolicyMatch selects modes; every condition given must hold
+type PolicyMatch struct {
+	Slug        globList          `yaml:"slug"`        // Any glob matches the slug
+	Path        globList          `yaml:"path"`        // Any glob matches the source path, at any depth like .gitignore
+	Frontmatter map[string]string `yaml:"frontmatter"` // Each key's source value matches its glob
+	Groups      []string          `yaml:"groups"`      // The mode has any of these groups
+	FileRegex   *bool             `yaml:"fileRegex"`   // Whether the written edit group carries a fileRegex
+}
+
+// PolicyRule applies an action to the modes its match selects, except those unless selects
+type PolicyRule struct {
+	Name    string       `yaml:"name"`
+	Match   PolicyMatch  `yaml:"match"`
+	Unless  *PolicyMatch `yaml:"unless"`
+	Action  PolicyAction `yaml:"action"`
+	Allow   []string     `yaml:"allow"` // Groups a downgraded mode keeps
+	Message string       `yaml:"message"`
+}
+
+// Policy is an organization's guardrails for converted modes, loaded with -policy
+type Policy struct {
+	Rules []PolicyRule `yaml:"rules"`
+}
+
+// PolicyViolation is one policy rule that matched a mode
+type PolicyViolation struct {
+	Rule    string
+	Action  PolicyAction
+	Message string
+}
+
+// PolicyError is returned when a policy rule denies a mode
+type PolicyError struct {
+	Slug      string
+	Violation PolicyViolation
+}
+
+func (e *PolicyError) Error() string {
+	return fmt.Sprintf("policy: %s denied by %s: %s", e.Slug, e.Violation.Rule, e.Violation.Message)
+}
+
+// LoadPolicy reads and validates a policy file
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading policy %s: %w", path, err)
+	}
+	var policy Policy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", path, err)
+	}
+
+	for i, rule := range policy.Rules {
+		if rule.Name == "" {
+			policy.Rules[i].Name = fmt.Sprintf("rule %d", i+1)
+		}
+		switch rule.Action {
+		case PolicyDeny, PolicyWarn:
+		case PolicyDowngrade:
+			if len(rule.Allow) == 0 {
+				return nil, fmt.Errorf("policy %s: downgrade needs the groups to allow", policy.Rules[i].Name)
+			}
+		default:
+			return nil, fmt.Errorf("policy %s: unknown action %q (use deny, downgrade or warn)", policy.Rules[i].Name, rule.Action)
+		}
+		for _, group := range append(append([]string{}, rule.Allow...), rule.Match.Groups...) {
+			if !isKiloGroup(group) {
+				return nil, fmt.Errorf("policy %s: unknown group %q", policy.Rules[i].Name, group)
+			}
+		}
+		for _, match := range []*PolicyMatch{&rule.Match, rule.Unless} {
+			if match == nil {
+				continue
+			}
+			for _, glob := range append(append([]string{}, match.Slug...), match.Path...) {
+				if _, err := globRegexp(glob, false); err != nil {
+					return nil, fmt.Errorf("policy %s: invalid glob %q: %w", policy.Rules[i].Name, glob, err)
+				}
+			}
+		}
+	}
+	return &policy, nil
+}
+
+// globRegexp compiles a glob where * and ? stop at slashes and ** crosses them. With
+// anyDepth the glob may also match a trailing part of a path, like a .gitignore pattern.
+func globRegexp(glob string, anyDepth bool) (*regexp.Regexp, error) {
+	var expr strings.Builder
+	expr.WriteString("^")
+	if anyDepth {
+		expr.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			expr.WriteString("[^/]*")
+		case glob[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.Compile(expr.String())
+}
+
+// matchGlob reports whether any of the globs matches value
+func matchGlob(globs []string, value string, anyDepth bool) bool {
+	for _, glob := range globs {
+		if re, err := globRegexp(glob, anyDepth); err == nil && re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceValue returns a frontmatter value of the agent as text, or "" when it has none
+func sourceValue(agent *SourceAgent, key string) string {
+	switch key {
+	case "name":
+		return agent.Name
+	case "description":
+		return agent.Description
+	case "model":
+		return agent.Model
+	case "tools":
+		return strings.Join(agent.Tools, ", ")
+	}
+	if value, ok := agent.Extras[key]; ok {
+		return fmt.Sprint(value)
+	}
+	return ""
+}
+
+// matches reports whether a mode converted from agent meets every condition
+func (m *PolicyMatch) matches(agent *SourceAgent, mode *KiloMode, path string) bool {
+	if len(m.Slug) > 0 && !matchGlob(m.Slug, mode.Slug, false) {
+		return false
+	}
+	if len(m.Path) > 0 && !matchGlob(m.Path, filepath.ToSlash(path), true) {
+		return false
+	}
+	for _, key := range sortedKeys(m.Frontmatter) {
+		if !matchGlob([]string{m.Frontmatter[key]}, sourceValue(agent, key), false) {
+			return false
+		}
+	}
+	if len(m.Groups) > 0 && !hasAnyGroup(mode.Groups, m.Groups) {
+		return false
+	}
+	if m.FileRegex != nil && *m.FileRegex != mode.restrictsEdit() {
+		return false
+	}
+	return true
+}
+
+// hasAnyGroup reports whether groups contains one of wanted
+func hasAnyGroup(groups, wanted []string) bool {
+	for _, group := range groups {
+		for _, w := range wanted {
+			if group == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Apply evaluates the rules in order against a converted mode, downgrading its groups as
+// rules require and recording each removed group as a loss. A deny stops evaluation and
+// is returned as a *PolicyError.
+func (p *Policy) Apply(agent *SourceAgent, mode *KiloMode, path string) error {
+	for _, rule := range p.Rules {
+		if !rule.Match.matches(agent, mode, path) || (rule.Unless != nil && rule.Unless.matches(agent, mode, path)) {
+			continue
+		}
+
+		violation := PolicyViolation{Rule: rule.Name, Action: rule.Action, Message: rule.Message}
+		switch rule.Action {
+		case PolicyDeny:
+			if violation.Message == "" {
+				violation.Message = "not allowed"
+			}
+			return &PolicyError{Slug: mode.Slug, Violation: violation}
+		case PolicyDowngrade:
+			var kept, removed []string
+			for _, group := range mode.Groups {
+				if hasAnyGroup(rule.Allow, []string{group}) {
+					kept = append(kept, group)
+				} else {
+					removed = append(removed, group)
+				}
+			}
+			if len(removed) == 0 {
+				continue
+			}
+			mode.Groups = kept
+			// Like groups removed by -max-groups, these are losses that -strict refuses
+			for _, group := range removed {
+				mode.Losses = append(mode.Losses, ConversionLoss{"groups", fmt.Sprintf("%s removed by policy %s", group, rule.Name)})
+			}
+			detail := fmt.Sprintf("removed %s", strings.Join(removed, ", "))
+			if violation.Message == "" {
+				violation.Message = detail
+			} else {
+				violation.Message += " (" + detail + ")"
+			}
+			mode.Traces = append(mode.Traces, DecisionTrace{
+				Field: "groups",
+				Value: strings.Join(kept, ", "),
+				Rule:  fmt.Sprintf("policy %s: downgraded, %s", rule.Name, detail),
+			})
+		case PolicyWarn:
+			if violation.Message == "" {
+				violation.Message = "matched"
+			}
+		}
+		mode.Violations = append(mode.Violations, violation)
+	}
+	return nil
+}
+
+// policyIssues turns a converted mode's policy violations into report issues
+func policyIssues(mode KiloMode, filePath string) []FileIssue {
+	var issues []FileIssue
+	for _, violation := range mode.Violations {
+		issues = append(issues, FileIssue{
+			FilePath:    filePath,
+			IssueType:   IssuePolicyViolation,
+			Description: fmt.Sprintf("%s: %s by %s: %s", mode.Slug, violation.Action, violation.Rule, violation.Message),
+			Suggestion:  issueSuggestions[IssuePolicyViolation],
+			Plugin:      mode.Plugin,
+		})
+	}
+	return issues
+}
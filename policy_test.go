@@ -0,0 +1,228 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testPolicy = `rules:
+  - name: mcp-allowlist
+    match: { groups: [mcp] }
+    unless: { slug: github-* }
+    action: deny
+    message: only approved agents may use MCP servers
+  - name: third-party-read-only
+    match: { path: "third-party/**" }
+    action: downgrade
+    allow: [read]
+  - name: unrestricted-edit
+    match: { groups: [edit], fileRegex: false }
+    action: warn
+    message: edit access is not restricted to file patterns
+`
+
+func writePolicy(t *testing.T, content string) *Policy {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := LoadPolicy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return policy
+}
+
+func TestPolicyApply(t *testing.T) {
+	policy := writePolicy(t, testPolicy)
+	agent := &SourceAgent{Name: "agent"}
+
+	// The allowlisted MCP mode converts; any other is denied
+	if err := policy.Apply(agent, &KiloMode{Slug: "github-triage", Groups: []string{"read", "mcp"}}, "agents/github-triage.md"); err != nil {
+		t.Errorf("Expected github-triage to be allowed, got %v", err)
+	}
+	err := policy.Apply(agent, &KiloMode{Slug: "ticket-bot", Groups: []string{"read", "mcp"}}, "agents/ticket-bot.md")
+	var policyErr *PolicyError
+	if !errors.As(err, &policyErr) || policyErr.Violation.Rule != "mcp-allowlist" {
+		t.Fatalf("Expected ticket-bot to be denied by mcp-allowlist, got %v", err)
+	}
+
+	// Vendored agents are downgraded at any depth, and the warn rule no longer matches
+	mode := &KiloMode{Slug: "vendor-dev", Groups: []string{"read", "edit", "command"}}
+	if err := policy.Apply(agent, mode, "repo/third-party/acme/dev.md"); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(mode.Groups, ", "); got != "read" {
+		t.Errorf("Expected a read-only downgrade, got %s", got)
+	}
+	if len(mode.Violations) != 1 || mode.Violations[0].Message != "removed edit, command" {
+		t.Errorf("Expected one downgrade violation, got %+v", mode.Violations)
+	}
+	if trace := mode.Traces[len(mode.Traces)-1]; trace.Field != "groups" || !strings.Contains(trace.Rule, "policy third-party-read-only") {
+		t.Errorf("Expected the downgrade in the groups trace, got %+v", trace)
+	}
+
+	// Unrestricted edit warns; a file restriction satisfies the rule
+	mode = &KiloMode{Slug: "writer", Groups: []string{"read", "edit"}}
+	if err := policy.Apply(agent, mode, "agents/writer.md"); err != nil || len(mode.Violations) != 1 || mode.Violations[0].Action != PolicyWarn {
+		t.Errorf("Expected a warning, got %v %+v", err, mode.Violations)
+	}
+	mode = &KiloMode{Slug: "writer", Groups: []string{"read", "edit"}, FileRegex: `\.md$`}
+	if err := policy.Apply(agent, mode, "agents/writer.md"); err != nil || len(mode.Violations) != 0 {
+		t.Errorf("Expected no violations, got %v %+v", err, mode.Violations)
+	}
+	if data, _ := NewConverter().marshalModes(CustomModesFile{CustomModes: []KiloMode{*mode}}); !strings.Contains(string(data), "fileRegex") {
+		t.Errorf("Expected the restriction that satisfied the rule to be written:\n%s", data)
+	}
+
+	// A restriction without an edit group restricts nothing that is written
+	policy = writePolicy(t, "rules:\n  - match: { fileRegex: true }\n    action: warn\n")
+	mode = &KiloMode{Slug: "reader", Groups: []string{"read"}, FileRegex: `\.md$`}
+	if policy.Apply(agent, mode, "agents/reader.md"); len(mode.Violations) != 0 {
+		t.Errorf("Expected no match for a restriction that is not written, got %+v", mode.Violations)
+	}
+}
+
+func TestPolicyApply_Frontmatter(t *testing.T) {
+	policy := writePolicy(t, "rules:\n  - match: { frontmatter: { model: opus* } }\n    action: warn\n")
+	mode := &KiloMode{Slug: "architect"}
+	if err := policy.Apply(&SourceAgent{Model: "opus-4"}, mode, "architect.md"); err != nil {
+		t.Fatal(err)
+	}
+	if len(mode.Violations) != 1 || mode.Violations[0].Rule != "rule 1" {
+		t.Errorf("Expected an unnamed rule to warn, got %+v", mode.Violations)
+	}
+	mode = &KiloMode{Slug: "architect"}
+	if policy.Apply(&SourceAgent{Model: "sonnet"}, mode, "architect.md"); len(mode.Violations) != 0 {
+		t.Errorf("Expected no match for another model, got %+v", mode.Violations)
+	}
+}
+
+func TestLoadPolicy_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"unknown action":          "rules:\n  - action: block\n",
+		"downgrade without allow": "rules:\n  - action: downgrade\n",
+		"unknown group":           "rules:\n  - action: downgrade\n    allow: [admin]\n",
+	}
+	for name, content := range cases {
+		path := filepath.Join(t.TempDir(), "policy.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadPolicy(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestConvertDirectory_Policy(t *testing.T) {
+	input := t.TempDir()
+	agents := map[string]string{
+		"bot.md":    "---\nname: ticket-bot\ndescription: Files tickets\n---\nUse the MCP server to file tickets.\n",
+		"writer.md": "---\nname: writer\ndescription: Writes docs\n---\nUpdate the documentation for each feature.\n",
+	}
+	for name, content := range agents {
+		if err := os.WriteFile(filepath.Join(input, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := NewConverter()
+	c.policy = writePolicy(t, testPolicy)
+	if err := c.convertDirectory(input, t.TempDir(), false, false); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := os.ReadFile(filepath.Join(input, "conversion-diagnostic-report.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"policy: ticket-bot denied by mcp-allowlist", "writer: warn by unrestricted-edit"} {
+		if !strings.Contains(string(report), want) {
+			t.Errorf("Expected %q in the report:\n%s", want, report)
+		}
+	}
+}
+
+func TestConvertDirectory_PolicySeesNamespacedSlug(t *testing.T) {
+	input := writeMarketplace(t)
+	output := t.TempDir()
+	c := NewConverter()
+	c.policy = writePolicy(t, "rules:\n  - name: no-beta\n    match: { slug: beta-* }\n    action: deny\n")
+	if err := c.convertDirectory(input, output, false, false); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(output, "custom_modes.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "slug: beta-") || !strings.Contains(string(data), "slug: alpha-python-pro") {
+		t.Errorf("Expected only the beta plugin's modes to be denied:\n%s", data)
+	}
+}
+
+func TestConvertDirectory_PolicyDeniesOneModeOfMany(t *testing.T) {
+	input := t.TempDir()
+	roomodes := `customModes:
+  - slug: ticket-bot
+    name: Ticket Bot
+    roleDefinition: You file tickets.
+    groups: [read, mcp]
+  - slug: docs-writer
+    name: Docs Writer
+    roleDefinition: You write documentation.
+    groups: [read, edit]
+`
+	if err := os.WriteFile(filepath.Join(input, ".roomodes"), []byte(roomodes), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := t.TempDir()
+	c := NewConverter()
+	c.policy = writePolicy(t, testPolicy)
+	if err := c.convertDirectory(input, output, false, false); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(output, "custom_modes.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "slug: ticket-bot") || !strings.Contains(string(data), "slug: docs-writer") {
+		t.Errorf("Expected only ticket-bot to be dropped:\n%s", data)
+	}
+	report, err := os.ReadFile(filepath.Join(input, "conversion-diagnostic-report.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(report), "policy: ticket-bot denied by mcp-allowlist") {
+		t.Errorf("Expected the denied mode in the report:\n%s", report)
+	}
+}
+
+func TestConvertDirectory_StrictRefusesPolicyDowngrade(t *testing.T) {
+	input := t.TempDir()
+	agent := "---\nname: vendor-dev\ndescription: Writes code\ntools: Read, Edit\n---\nYou write code.\n"
+	writeTestFile(t, input, "third-party/dev.md", agent)
+
+	c := NewConverter()
+	c.strict = true
+	c.policy = writePolicy(t, testPolicy)
+	err := c.convertDirectory(input, t.TempDir(), false, false)
+	if err == nil || !strings.Contains(err.Error(), "strict mode") {
+		t.Fatalf("Expected strict mode to refuse the downgraded mode, got %v", err)
+	}
+
+	report, err := os.ReadFile(filepath.Join(input, "conversion-diagnostic-report.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(report), "edit removed by policy third-party-read-only") {
+		t.Errorf("Expected the removed group in the report:\n%s", report)
+	}
+}
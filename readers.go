@@ -0,0 +1,373 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// errUnsupportedFormat is returned when no reader recognizes an input file
+var errUnsupportedFormat = errors.New("unsupported input format")
+
+var (
+	frontmatterKeyRe  = regexp.MustCompile(`^([A-Za-z_][\w-]*):`)
+	tomlKeyRe         = regexp.MustCompile(`^([A-Za-z_][\w-]*)\s*=`)
+	customModesKeyRe  = regexp.MustCompile(`(?m)^\s*\{?\s*"?customModes"?\s*:`)
+	unquotedGlobValue = regexp.MustCompile(`^(\s*globs:\s*)([*!].*)$`)
+)
+
+// readSource reads a file and parses it with the first reader that recognizes it
+func (c *Converter) readSource(filePath string) ([]*SourceAgent, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %s: %w", filePath, err)
+	}
+
+	reader := c.detectReader(filePath, content)
+	if reader == nil {
+		return nil, fmt.Errorf("%w: %s", errUnsupportedFormat, filepath.Base(filePath))
+	}
+
+	agents, err := reader.Read(filePath, content)
+	if err != nil {
+		var fmErr *FrontmatterError
+		if errors.As(err, &fmErr) {
+			fmErr.Path = filePath
+		}
+		return nil, err
+	}
+	if len(agents) == 0 {
+		return nil, fmt.Errorf("no agents found in %s", filepath.Base(filePath))
+	}
+
+	for _, agent := range agents {
+		agent.Format = reader.Name()
+		agent.SourcePath = filePath
+	}
+
+	// Agents read from one frontmatter block share its sanitization record
+	if c.showSanitization && agents[0].Sanitization != nil {
+		fmt.Print(indentLines(agents[0].Sanitization.Diff(filePath), "    "))
+	}
+
+	return agents, nil
+}
+
+// detectReader returns the first reader that recognizes the file, or nil
+func (c *Converter) detectReader(path string, content []byte) Reader {
+	for _, reader := range c.readers {
+		if reader.Detect(path, content) {
+			return reader
+		}
+	}
+	return nil
+}
+
+// isCandidateFile reports whether a file name could hold agents for any reader
+func isCandidateFile(name string) bool {
+	lower := strings.ToLower(name)
+	if lower == ".roomodes" {
+		return true
+	}
+	if lower == sourceConfigName {
+		return false
+	}
+	switch filepath.Ext(lower) {
+	case ".md", ".mdc", ".json", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// frontmatterKeys returns the top-level keys of a file's frontmatter, if it has any
+func frontmatterKeys(content []byte) map[string]bool {
+	keys := make(map[string]bool)
+	block, err := splitFrontmatter(string(content))
+	if err != nil {
+		return keys
+	}
+	keyRe := frontmatterKeyRe
+	if block.Format == "toml" {
+		keyRe = tomlKeyRe
+	}
+	for _, line := range strings.Split(block.Content, "\n") {
+		if match := keyRe.FindStringSubmatch(line); match != nil {
+			keys[match[1]] = true
+		}
+	}
+	return keys
+}
+
+// baseNameWithout strips a (case-insensitive) suffix from the file's base name
+func baseNameWithout(path, suffix string) string {
+	base := filepath.Base(path)
+	if strings.HasSuffix(strings.ToLower(base), suffix) {
+		return base[:len(base)-len(suffix)]
+	}
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// firstParagraph returns the first non-heading paragraph of a markdown body
+func firstParagraph(markdown string) string {
+	var paragraph []string
+	for _, line := range strings.Split(markdown, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		paragraph = append(paragraph, line)
+	}
+	return strings.Join(paragraph, " ")
+}
+
+// claudeReader reads Claude Code sub-agent markdown files
+type claudeReader struct {
+	converter *Converter
+}
+
+func (r *claudeReader) Name() string { return "claude" }
+
+func (r *claudeReader) Detect(path string, content []byte) bool {
+	return strings.EqualFold(filepath.Ext(path), ".md")
+}
+
+func (r *claudeReader) Read(path string, content []byte) ([]*SourceAgent, error) {
+	agent, markdown, sanitization, err := r.converter.parseFrontmatterWithStats(string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	// Kilo modes have no model setting, so it travels with the other extras
+	extras := make(map[string]interface{})
+	for key, value := range agent.Extras {
+		extras[key] = value
+	}
+	if agent.Model != "" {
+		extras["model"] = agent.Model
+	}
+
+	return []*SourceAgent{{
+		Name:         agent.Name,
+		Description:  agent.Description,
+		Model:        agent.Model,
+		Tools:        agent.Tools,
+		Body:         markdown,
+		Sanitization: sanitization,
+		Extras:       extras,
+	}}, nil
+}
+
+// copilotChatMode represents the frontmatter of a GitHub Copilot .chatmode.md file
+type copilotChatMode struct {
+	Description string   `yaml:"description"`
+	Tools       []string `yaml:"tools,omitempty"`
+	Model       string   `yaml:"model,omitempty"`
+}
+
+// copilotReader reads GitHub Copilot custom chat modes
+type copilotReader struct {
+	converter *Converter
+}
+
+func (r *copilotReader) Name() string { return "copilot" }
+
+func (r *copilotReader) Detect(path string, content []byte) bool {
+	lower := strings.ToLower(filepath.Base(path))
+	if strings.HasSuffix(lower, ".chatmode.md") {
+		return true
+	}
+	// Chat modes have no name key; the mode is named after its file
+	if filepath.Ext(lower) == ".md" {
+		keys := frontmatterKeys(content)
+		return !keys["name"] && keys["tools"] && keys["description"]
+	}
+	return false
+}
+
+func (r *copilotReader) Read(path string, content []byte) ([]*SourceAgent, error) {
+	block, err := splitFrontmatter(string(content))
+	if err != nil {
+		return nil, err
+	}
+	markdown := block.Body
+
+	var chatMode copilotChatMode
+	sanitization, err := r.converter.decodeFrontmatter(block, &chatMode)
+	if err != nil {
+		return nil, err
+	}
+
+	description := chatMode.Description
+	if description == "" {
+		description = firstParagraph(markdown)
+	}
+	if description == "" {
+		return nil, fmt.Errorf("missing required 'description' field")
+	}
+
+	return []*SourceAgent{{
+		Name:         baseNameWithout(path, ".chatmode.md"),
+		Description:  description,
+		Model:        chatMode.Model,
+		Tools:        chatMode.Tools,
+		Body:         markdown,
+		Sanitization: sanitization,
+	}}, nil
+}
+
+// cursorRule represents the frontmatter of a Cursor .mdc project rule
+type cursorRule struct {
+	Description string `yaml:"description"`
+	Globs       string `yaml:"globs"`
+	AlwaysApply bool   `yaml:"alwaysApply"`
+}
+
+// cursorReader reads Cursor project rules
+type cursorReader struct {
+	converter *Converter
+}
+
+func (r *cursorReader) Name() string { return "cursor" }
+
+func (r *cursorReader) Detect(path string, content []byte) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".mdc" {
+		return true
+	}
+	if ext == ".md" {
+		keys := frontmatterKeys(content)
+		return !keys["name"] && (keys["globs"] || keys["alwaysApply"])
+	}
+	return false
+}
+
+func (r *cursorReader) Read(path string, content []byte) ([]*SourceAgent, error) {
+	block, err := splitFrontmatter(string(content))
+	if err != nil {
+		return nil, err
+	}
+	markdown := block.Body
+
+	// Cursor writes globs unquoted, which YAML reads as an alias
+	lines := strings.Split(block.Content, "\n")
+	for i, line := range lines {
+		if match := unquotedGlobValue.FindStringSubmatch(line); match != nil {
+			lines[i] = match[1] + `"` + strings.ReplaceAll(strings.TrimSpace(match[2]), `"`, `\"`) + `"`
+		}
+	}
+
+	block.Content = strings.Join(lines, "\n")
+
+	var rule cursorRule
+	sanitization, err := r.converter.decodeFrontmatter(block, &rule)
+	if err != nil {
+		return nil, err
+	}
+
+	description := rule.Description
+	if description == "" {
+		description = firstParagraph(markdown)
+	}
+	if description == "" {
+		return nil, fmt.Errorf("missing required 'description' field")
+	}
+
+	agent := &SourceAgent{
+		Name:         baseNameWithout(path, ".mdc"),
+		Description:  description,
+		Body:         markdown,
+		Sanitization: sanitization,
+	}
+	if globs := strings.TrimSpace(rule.Globs); globs != "" {
+		agent.WhenToUse = fmt.Sprintf("Use this mode when working with files matching %s.", globs)
+	}
+
+	return []*SourceAgent{agent}, nil
+}
+
+// rooModesFile represents a Roo Code .roomodes file in YAML or JSON form
+type rooModesFile struct {
+	CustomModes []rooMode `yaml:"customModes"`
+}
+
+// rooMode represents a single Roo Code custom mode
+type rooMode struct {
+	Slug               string        `yaml:"slug"`
+	Name               string        `yaml:"name"`
+	IconName           string        `yaml:"iconName"`
+	RoleDefinition     string        `yaml:"roleDefinition"`
+	WhenToUse          string        `yaml:"whenToUse"`
+	Description        string        `yaml:"description"`
+	CustomInstructions string        `yaml:"customInstructions"`
+	Groups             []interface{} `yaml:"groups"`
+}
+
+// rooReader reads Roo Code .roomodes files
+type rooReader struct{}
+
+func (r *rooReader) Name() string { return "roo" }
+
+func (r *rooReader) Detect(path string, content []byte) bool {
+	base := strings.ToLower(filepath.Base(path))
+	if base == ".roomodes" {
+		return true
+	}
+	switch filepath.Ext(base) {
+	case ".json", ".yaml", ".yml":
+		return customModesKeyRe.Match(content)
+	}
+	return false
+}
+
+func (r *rooReader) Read(path string, content []byte) ([]*SourceAgent, error) {
+	// YAML is a superset of JSON, so one decoder handles both forms
+	var file rooModesFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("invalid modes file: %w", err)
+	}
+
+	var agents []*SourceAgent
+	for i, mode := range file.CustomModes {
+		if mode.Slug == "" {
+			return nil, fmt.Errorf("mode %d: missing required 'slug' field", i+1)
+		}
+		if mode.RoleDefinition == "" {
+			return nil, fmt.Errorf("mode %q: missing required 'roleDefinition' field", mode.Slug)
+		}
+
+		description := mode.Description
+		if description == "" {
+			description = mode.RoleDefinition
+		}
+
+		agent := &SourceAgent{
+			Name:           mode.Slug,
+			Description:    description,
+			Body:           strings.TrimSpace(mode.CustomInstructions),
+			Slug:           mode.Slug,
+			DisplayName:    mode.Name,
+			IconName:       mode.IconName,
+			RoleDefinition: mode.RoleDefinition,
+			WhenToUse:      mode.WhenToUse,
+			Summary:        mode.Description,
+		}
+		agent.Groups, agent.FileRegex, agent.FileRegexDescription = flattenGroups(mode.Groups)
+		agents = append(agents, agent)
+	}
+
+	return agents, nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func writeTestFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	return path
+}
+
+func TestDetectReader(t *testing.T) {
+	c := NewConverter()
+	cases := []struct {
+		path    string
+		content string
+		want    string
+	}{
+		{"agent.md", "---\nname: a\ndescription: d\n---\n", "claude"},
+		{"review.chatmode.md", "---\ndescription: d\n---\n", "copilot"},
+		{"plan.md", "---\ndescription: d\ntools: ['codebase']\n---\n", "copilot"},
+		{"go.mdc", "---\ndescription: d\n---\n", "cursor"},
+		{"style.md", "---\nglobs: \"*.go\"\nalwaysApply: false\n---\n", "cursor"},
+		{".roomodes", "customModes: []", "roo"},
+		{"modes.json", `{"customModes": []}`, "roo"},
+	}
+	for _, tc := range cases {
+		reader := c.detectReader(tc.path, []byte(tc.content))
+		if reader == nil || reader.Name() != tc.want {
+			t.Errorf("%s: expected reader %q, got %v", tc.path, tc.want, reader)
+		}
+	}
+	if reader := c.detectReader("package.json", []byte(`{"name": "x"}`)); reader != nil {
+		t.Errorf("Expected no reader for unrelated JSON, got %q", reader.Name())
+	}
+}
+
+func TestRooReader_YAMLAndJSON(t *testing.T) {
+	dir := t.TempDir()
+	c := NewConverter()
+
+	yamlPath := writeTestFile(t, dir, ".roomodes", `customModes:
+  - slug: docs-writer
+    name: 📝 Docs Writer
+    roleDefinition: You are a technical writer.
+    groups:
+      - read
+      - - edit
+        - fileRegex: \.md$
+          description: Markdown files only
+`)
+	modes, _, err := c.convertSource(yamlPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(modes) != 1 || modes[0].Slug != "docs-writer" || modes[0].Name != "📝 Docs Writer" {
+		t.Fatalf("Unexpected modes: %+v", modes)
+	}
+	if modes[0].FileRegex != `\.md$` || len(modes[0].Groups) != 2 {
+		t.Errorf("Expected groups and file restriction to carry over, got %+v", modes[0])
+	}
+
+	// The restriction is written back in Kilo's [edit, {fileRegex}] form, in both formats
+	for _, format := range []string{"yaml", "json"} {
+		c.outputFormat = format
+		data, err := c.marshalModes(CustomModesFile{CustomModes: modes})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "fileRegex") || !strings.Contains(string(data), "Markdown files only") {
+			t.Errorf("%s: expected the edit restriction in the output:\n%s", format, data)
+		}
+		var decoded CustomModesFile
+		if format == "json" {
+			err = json.Unmarshal(data, &decoded)
+		} else {
+			err = yaml.Unmarshal(data, &decoded)
+		}
+		if err != nil || decoded.CustomModes[0].FileRegex != `\.md$` || strings.Join(decoded.CustomModes[0].Groups, ",") != "read,edit" {
+			t.Errorf("%s: expected the restriction to round-trip, got %+v, %v", format, decoded.CustomModes, err)
+		}
+	}
+	c.outputFormat = "yaml"
+
+	jsonPath := writeTestFile(t, dir, "modes.json", `{"customModes": [
+  {"slug": "a", "name": "A", "roleDefinition": "You are A.", "groups": ["read"]},
+  {"slug": "b", "name": "B", "roleDefinition": "You are B.", "groups": ["read", "command"]}
+]}`)
+	modes, _, err = c.convertSource(jsonPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(modes) != 2 || modes[1].Slug != "b" {
+		t.Errorf("Expected two modes from JSON, got %+v", modes)
+	}
+}
+
+func TestRooReader_MissingSlug(t *testing.T) {
+	r := &rooReader{}
+	_, err := r.Read(".roomodes", []byte("customModes:\n  - name: A\n    roleDefinition: x\n"))
+	if err == nil {
+		t.Error("Expected error for missing slug, got nil")
+	}
+}
+
+func TestCopilotReader(t *testing.T) {
+	dir := t.TempDir()
+	c := NewConverter()
+	path := writeTestFile(t, dir, "security-review.chatmode.md", `---
+description: Review code for security vulnerabilities
+tools: ['codebase', 'search']
+model: GPT-4.1
+---
+You are a security reviewer.`)
+	modes, _, err := c.convertSource(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if modes[0].Slug != "security-review" || modes[0].OriginalModel != "GPT-4.1" {
+		t.Errorf("Unexpected mode: %+v", modes[0])
+	}
+}
+
+func TestCursorReader_UnquotedGlobs(t *testing.T) {
+	dir := t.TempDir()
+	c := NewConverter()
+	path := writeTestFile(t, dir, "typescript.mdc", `---
+description:
+globs: *.ts, *.tsx
+alwaysApply: false
+---
+# TypeScript rules
+
+Prefer strict types everywhere.`)
+	modes, _, err := c.convertSource(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if modes[0].RoleDefinition != "Prefer strict types everywhere." {
+		t.Errorf("Expected description from first paragraph, got %q", modes[0].RoleDefinition)
+	}
+	if modes[0].WhenToUse != "Use this mode when working with files matching *.ts, *.tsx." {
+		t.Errorf("Unexpected whenToUse: %q", modes[0].WhenToUse)
+	}
+}
+
+func TestReadSource_Unsupported(t *testing.T) {
+	dir := t.TempDir()
+	c := NewConverter()
+	path := writeTestFile(t, dir, "package.json", `{"name": "x"}`)
+	if _, err := c.readSource(path); err == nil {
+		t.Error("Expected unsupported format error, got nil")
+	}
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	// "You are …" / "You're …" opening an identity paragraph
+	identityRe = regexp.MustCompile(`^(?i:you are|you're)\b`)
+
+	// Sentences that only tell Claude when to route to the agent
+	routingSentenceRe = regexp.MustCompile(`(?i)\b(use (it |this agent |this subagent )?(proactively|immediately)|must be used|should be (used|invoked)|invoke (this|the) agent)\b`)
+
+	// "Use this agent when you need to review code" keeps its task as "You review code"
+	useAgentToRe = regexp.MustCompile(`(?i)^use (?:this|the) (?:agent|subagent|mode)\s+(?:(?:when(?:ever)?|if) (?:you|the user) (?:need|needs|want|wants) to|to)\s+(.+)$`)
+	useAgentRe   = regexp.MustCompile(`(?i)^use (?:this|the) (?:agent|subagent|mode)\b`)
+
+	// Standalone shouting adverbs left behind in descriptions
+	routingWordRe = regexp.MustCompile(`\s*\b(PROACTIVELY|IMMEDIATELY)\b`)
+
+	sentenceEndRe = regexp.MustCompile(`([.!?])\s+`)
+	articleRe     = regexp.MustCompile(`^(?i:a|an|the)\s`)
+)
+
+// imperativeVerbs are verbs Claude descriptions commonly start with ("Write idiomatic Python…")
+var imperativeVerbs = map[string]bool{
+	"analyze": true, "architect": true, "assist": true, "audit": true, "automate": true,
+	"build": true, "conduct": true, "configure": true, "convert": true, "coordinate": true,
+	"craft": true, "create": true, "debug": true, "deploy": true, "design": true,
+	"develop": true, "diagnose": true, "document": true, "draft": true, "ensure": true,
+	"evaluate": true, "explain": true, "fix": true, "generate": true, "guide": true,
+	"handle": true, "help": true, "identify": true, "implement": true, "improve": true,
+	"integrate": true, "investigate": true, "maintain": true, "manage": true,
+	"migrate": true, "monitor": true, "optimize": true, "orchestrate": true,
+	"perform": true, "plan": true, "prefer": true, "profile": true, "provide": true,
+	"refactor": true, "research": true, "resolve": true, "review": true, "scale": true,
+	"secure": true, "specialize": true, "streamline": true, "teach": true,
+	"test": true, "transform": true, "translate": true, "troubleshoot": true, "tune": true,
+	"write": true,
+}
+
+// roleNouns head noun phrases like "Test automation expert", which start with a verb-like word
+var roleNouns = map[string]bool{
+	"expert": true, "specialist": true, "engineer": true, "architect": true, "developer": true,
+	"designer": true, "analyst": true, "consultant": true, "auditor": true, "reviewer": true,
+	"assistant": true, "agent": true, "manager": true, "strategist": true, "writer": true,
+	"scientist": true, "administrator": true, "guru": true, "master": true, "advisor": true,
+	"coordinator": true, "lead": true, "tester": true, "planner": true, "researcher": true,
+	"mentor": true, "tutor": true, "coach": true, "helper": true, "bot": true, "editor": true,
+	"operator": true, "maintainer": true, "partner": true,
+}
+
+// leadWords are role nouns and adjectives that read better lowercased after "You are a"
+var leadWords = map[string]bool{
+	"expert": true, "senior": true, "elite": true, "specialist": true, "professional": true,
+	"experienced": true, "seasoned": true, "principal": true, "lead": true, "staff": true,
+	"master": true, "world-class": true, "backend": true, "frontend": true, "full-stack": true,
+	"fullstack": true, "cloud": true, "security": true, "performance": true, "code": true,
+	"database": true, "data": true, "mobile": true, "technical": true, "strategic": true,
+	"meticulous": true, "autonomous": true, "devops": true, "systems": true, "software": true,
+}
+
+// buildRoleDefinition synthesizes Kilo's identity statement for a Claude agent. It prefers
+// the body's opening "You are …" paragraph and otherwise rewrites the description.
+// The second result explains the fallback when only the name was usable.
+func buildRoleDefinition(displayName, description, body string) (string, string) {
+	if identity := identityParagraph(body); identity != "" {
+		return identity, ""
+	}
+	if rewritten := secondPerson(displayName, description); rewritten != "" {
+		return rewritten, ""
+	}
+	return "You are " + withArticle(displayName) + ".", "description has only routing hints; built from the name"
+}
+
+// identityParagraph returns the first "You are …" paragraph among the body's opening paragraphs
+func identityParagraph(body string) string {
+	const searched = 3
+	count := 0
+	inFence := false
+	var paragraph []string
+
+	flush := func() string {
+		text := strings.Join(paragraph, " ")
+		paragraph = nil
+		if text == "" {
+			return ""
+		}
+		count++
+		if identityRe.MatchString(text) {
+			return text
+		}
+		return ""
+	}
+
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ") {
+			if found := flush(); found != "" {
+				return found
+			}
+			if count >= searched {
+				return ""
+			}
+			continue
+		}
+		paragraph = append(paragraph, line)
+	}
+	return flush()
+}
+
+// secondPerson rewrites a description into a second-person identity statement,
+// dropping routing-only sentences. It returns "" when nothing but routing is left.
+// A description that opens with neither a noun phrase nor a verb follows "You are <name>."
+func secondPerson(displayName, description string) string {
+	var kept []string
+	for _, sentence := range splitSentences(collapseWhitespace(description)) {
+		if routingSentenceRe.MatchString(sentence) {
+			continue
+		}
+		sentence = strings.TrimSpace(routingWordRe.ReplaceAllString(sentence, ""))
+		if match := useAgentToRe.FindStringSubmatch(sentence); match != nil {
+			sentence = match[1]
+		} else if useAgentRe.MatchString(sentence) {
+			continue
+		}
+		if sentence != "" {
+			kept = append(kept, sentence)
+		}
+	}
+	if len(kept) == 0 {
+		return ""
+	}
+
+	first := kept[0]
+	switch {
+	case identityRe.MatchString(first):
+		// Already an identity statement
+	case articleRe.MatchString(first):
+		first = "You are " + strings.ToLower(first[:1]) + first[1:]
+	case startsWithVerb(first):
+		first = "You " + baseVerbs(first)
+	case startsWithNounPhrase(first):
+		first = "You are " + withArticle(first)
+	case thirdPersonVerb(first) != "":
+		_, rest, _ := strings.Cut(first, " ")
+		first = "You " + thirdPersonVerb(first) + " " + rest
+	default:
+		first = "You are " + withArticle(displayName) + ". " + first
+	}
+	kept[0] = first
+
+	// Later sentences continue in the same voice: "Optimizes performance" → "You optimize performance"
+	for i := 1; i < len(kept); i++ {
+		if startsWithVerb(kept[i]) {
+			kept[i] = "You " + baseVerbs(kept[i])
+		}
+	}
+
+	result := strings.Join(kept, " ")
+	if !strings.ContainsAny(result[len(result)-1:], ".!?") {
+		result += "."
+	}
+	return result
+}
+
+// startsWithVerb reports whether a sentence opens with an imperative or third-person verb
+// rather than a noun phrase such as "Test automation expert"
+func startsWithVerb(sentence string) bool {
+	words := strings.Fields(strings.ToLower(sentence))
+	if len(words) == 0 {
+		return false
+	}
+	if baseVerb(words[0]) == "" {
+		return false
+	}
+	if len(words) > 1 && (words[1] == "a" || words[1] == "an" || words[1] == "the") {
+		return true
+	}
+	for _, word := range words[1:min(len(words), 4)] {
+		if roleNouns[strings.Trim(word, ",.;:")] {
+			return false
+		}
+	}
+	return true
+}
+
+// startsWithNounPhrase reports whether a sentence opens with a role such as "Senior Go
+// engineer" or "Design system specialist for …", which reads well after "You are a"
+func startsWithNounPhrase(sentence string) bool {
+	words := strings.Fields(strings.ToLower(sentence))
+	if len(words) == 0 {
+		return false
+	}
+	if leadWords[words[0]] {
+		return true
+	}
+	for _, word := range words[:min(len(words), 4)] {
+		if roleNouns[strings.Trim(word, ",.;:")] {
+			return true
+		}
+	}
+	return false
+}
+
+// thirdPersonVerb returns the base form of a capitalized third-person verb opening a
+// sentence and followed by its object ("Answers questions" → "answer"), or ""
+func thirdPersonVerb(sentence string) string {
+	words := strings.Fields(sentence)
+	if len(words) < 2 || !unicode.IsUpper(rune(words[0][0])) || isAcronym(words[0]) {
+		return ""
+	}
+	word := strings.ToLower(words[0])
+	if !strings.HasSuffix(word, "s") || strings.HasSuffix(word, "ss") || strings.HasSuffix(word, "us") || strings.HasSuffix(word, "is") {
+		return ""
+	}
+	// "Tools are …" opens with a plural noun, not a verb
+	switch strings.ToLower(words[1]) {
+	case "are", "were", "is", "was", "have", "has", "can", "will", "should", "must":
+		return ""
+	}
+	switch {
+	case word == "has":
+		return "have"
+	case word == "does" || word == "goes":
+		return strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "ies"):
+		return strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "ches") || strings.HasSuffix(word, "shes") || strings.HasSuffix(word, "sses") || strings.HasSuffix(word, "xes") || strings.HasSuffix(word, "zes"):
+		return strings.TrimSuffix(word, "es")
+	}
+	return strings.TrimSuffix(word, "s")
+}
+
+// baseVerb returns the imperative form of a known verb ("reviews" → "review"), or ""
+func baseVerb(word string) string {
+	for _, candidate := range []string{word, strings.TrimSuffix(word, "s"), strings.TrimSuffix(word, "es")} {
+		if imperativeVerbs[candidate] {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// baseVerbs lowercases a clause's leading verb and puts it, and the verbs that open
+// its comma- or "and"-separated parts, into the imperative form
+func baseVerbs(clause string) string {
+	words := strings.Fields(clause)
+	for i, word := range words {
+		lower := strings.ToLower(word)
+		if i == 0 || strings.HasSuffix(words[i-1], ",") || words[i-1] == "and" || words[i-1] == "or" {
+			if verb := baseVerb(lower); verb != "" && (i == 0 || verb != lower) {
+				words[i] = verb
+			}
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// splitSentences splits text after sentence-ending punctuation
+func splitSentences(text string) []string {
+	var sentences []string
+	for _, part := range strings.Split(sentenceEndRe.ReplaceAllString(text, "$1\n"), "\n") {
+		if part = strings.TrimSpace(part); part != "" {
+			sentences = append(sentences, part)
+		}
+	}
+	return sentences
+}
+
+// joinWords joins non-empty words with spaces
+func joinWords(words ...string) string {
+	var parts []string
+	for _, word := range words {
+		if word != "" {
+			parts = append(parts, word)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// withArticle prefixes a noun phrase with "a" or "an", lowercasing a common lead word
+func withArticle(phrase string) string {
+	word, rest, _ := strings.Cut(phrase, " ")
+	if lower := strings.ToLower(word); leadWords[lower] || imperativeVerbs[lower] {
+		word = lower
+	}
+	phrase = joinWords(word, rest)
+
+	article := "a"
+	if isAcronym(word) {
+		// Letters whose names start with a vowel sound: "an AI engineer", "an ML pipeline"
+		if strings.ContainsRune("AEFHILMNORSX", rune(word[0])) {
+			article = "an"
+		}
+	} else if word != "" && strings.ContainsRune("aeioAEIO", rune(word[0])) {
+		article = "an"
+	}
+	return article + " " + phrase
+}
+
+// isAcronym reports whether a word is written in capitals, like "AI" or "SQL"
+func isAcronym(word string) bool {
+	letters := 0
+	for _, r := range word {
+		if unicode.IsLower(r) {
+			return false
+		}
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	return letters >= 2
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// TestBuildRoleDefinition_Golden converts each sample agent in testdata/roledefinition
+// and compares its roleDefinition with the matching .golden file
+func TestBuildRoleDefinition_Golden(t *testing.T) {
+	samples, _ := filepath.Glob(filepath.Join("testdata", "roledefinition", "*.md"))
+	if len(samples) == 0 {
+		t.Fatal("No samples found")
+	}
+
+	c := NewConverter()
+	for _, sample := range samples {
+		modes, _, err := c.convertSource(sample)
+		if err != nil {
+			t.Fatalf("%s: %v", sample, err)
+		}
+		got := modes[0].RoleDefinition + "\n"
+
+		golden := strings.TrimSuffix(sample, ".md") + ".golden"
+		if *updateGolden {
+			if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatalf("%s: %v (run go test -update to create it)", sample, err)
+		}
+		if got != string(want) {
+			t.Errorf("%s:\nexpected: %s     got: %s", sample, want, got)
+		}
+	}
+}
+
+func TestSecondPerson(t *testing.T) {
+	cases := map[string]string{
+		"Write idiomatic Go. Use PROACTIVELY for Go refactoring.": "You write idiomatic Go.",
+		"Analyzes logs and metrics":                               "You analyze logs and metrics.",
+		"Design system specialist for component libraries.":       "You are a design system specialist for component libraries.",
+		"The team's release manager.":                             "You are the team's release manager.",
+		"You are already an identity statement.":                  "You are already an identity statement.",
+		"Use this agent when the user asks about billing.":        "",
+	}
+	for input, want := range cases {
+		if got := secondPerson("Billing Bot", input); got != want {
+			t.Errorf("%q: expected %q, got %q", input, want, got)
+		}
+	}
+}
+
+func TestBuildMode_IdentityParagraphNotRepeated(t *testing.T) {
+	modes, _, err := NewConverter().convertSource(filepath.Join("testdata", "roledefinition", "ai-engineer.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mode := modes[0]
+	if strings.Contains(mode.CustomInstructions, mode.RoleDefinition) {
+		t.Errorf("Expected the identity paragraph only in roleDefinition, got instructions:\n%s", mode.CustomInstructions)
+	}
+	if !strings.HasPrefix(mode.CustomInstructions, "## Focus Areas") {
+		t.Errorf("Expected the instructions to start at the first section, got:\n%s", mode.CustomInstructions)
+	}
+}
+
+func TestWithArticle(t *testing.T) {
+	cases := map[string]string{
+		"AI engineer":       "an AI engineer",
+		"SQL expert":        "an SQL expert",
+		"GraphQL architect": "a GraphQL architect",
+		"Expert in Rust":    "an expert in Rust",
+		"Python developer":  "a Python developer",
+	}
+	for input, want := range cases {
+		if got := withArticle(input); got != want {
+			t.Errorf("%q: expected %q, got %q", input, want, got)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SectionTarget is where the splitter sends a markdown section of an agent body
+type SectionTarget string
+
+const (
+	TargetRole         SectionTarget = "role"         // Appended to roleDefinition
+	TargetInstructions SectionTarget = "instructions" // Kept in customInstructions
+	TargetRules        SectionTarget = "rules"        // Written to .kilocode/rules-<slug>/NN-section.md
+	TargetDrop         SectionTarget = "drop"         // Left out of the mode
+)
+
+// SectionRule routes sections whose heading matches a case-insensitive regular expression
+type SectionRule struct {
+	Match  string        `yaml:"match"`
+	Target SectionTarget `yaml:"target"`
+	re     *regexp.Regexp
+}
+
+// SplitConfig configures how prompt bodies are split between mode fields and rule files
+type SplitConfig struct {
+	Rules               []SectionRule `yaml:"rules"`
+	Default             SectionTarget `yaml:"default"`
+	MaxInstructionChars int           `yaml:"maxInstructionChars"` // Later instruction sections overflow to rule files; 0 means no limit
+}
+
+// defaultSplitConfig keeps the persona in roleDefinition, procedures in customInstructions
+// and reference material in rule files
+var defaultSplitConfig = SplitConfig{
+	Rules: []SectionRule{
+		{Match: `^(role|persona|identity|about|who you are|expertise|focus areas?|core competencies|purpose)\b`, Target: TargetRole},
+		{Match: `^(approach|process|workflow|methodology|procedures?|steps|checklist|guidelines|best practices|constraints|output|deliverables|response format)\b`, Target: TargetInstructions},
+		{Match: `^(examples?|reference|references|appendix|templates?|resources|glossary)\b`, Target: TargetRules},
+	},
+	Default: TargetInstructions,
+}
+
+// RuleFile is a mode-specific rule file written to .kilocode/rules-<slug>/
+type RuleFile struct {
+	Name    string
+	Content string
+}
+
+// SectionSplitter routes the markdown sections of agent bodies by heading
+type SectionSplitter struct {
+	config SplitConfig
+}
+
+// markdownSection is a top-level (# or ##) section of a markdown body
+type markdownSection struct {
+	Heading string // Empty for the text before the first heading
+	Text    string // Full section text, including the heading line
+}
+
+// splitResult is a body divided between mode fields and rule files
+type splitResult struct {
+	Role         []string
+	Instructions string
+	RuleFiles    []RuleFile
+}
+
+var (
+	sectionHeadingRe = regexp.MustCompile(`^#{1,2}\s+(.+?)\s*#*$`)
+	ruleNameRe       = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// LoadSplitConfig reads section routing rules from a YAML file
+func LoadSplitConfig(path string) (*SplitConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading split rules %s: %w", path, err)
+	}
+	var config SplitConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid split rules %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// NewSectionSplitter validates a split configuration; nil selects the default rules
+func NewSectionSplitter(config *SplitConfig) (*SectionSplitter, error) {
+	if config == nil {
+		config = &defaultSplitConfig
+	}
+	splitter := &SectionSplitter{config: *config}
+	splitter.config.Rules = make([]SectionRule, len(config.Rules))
+
+	if splitter.config.Default == "" {
+		splitter.config.Default = TargetInstructions
+	}
+	if !validSectionTarget(splitter.config.Default) {
+		return nil, fmt.Errorf("unknown default section target %q", splitter.config.Default)
+	}
+
+	for i, rule := range config.Rules {
+		if !validSectionTarget(rule.Target) {
+			return nil, fmt.Errorf("rule %q: unknown section target %q (use role, instructions, rules or drop)", rule.Match, rule.Target)
+		}
+		re, err := regexp.Compile("(?i)" + rule.Match)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.Match, err)
+		}
+		rule.re = re
+		splitter.config.Rules[i] = rule
+	}
+	return splitter, nil
+}
+
+// validSectionTarget reports whether target is one of the known section targets
+func validSectionTarget(target SectionTarget) bool {
+	switch target {
+	case TargetRole, TargetInstructions, TargetRules, TargetDrop:
+		return true
+	}
+	return false
+}
+
+// target returns where a section with the given heading goes
+func (s *SectionSplitter) target(heading string) SectionTarget {
+	for _, rule := range s.config.Rules {
+		if rule.re.MatchString(heading) {
+			return rule.Target
+		}
+	}
+	return s.config.Default
+}
+
+// Split routes a body's sections. The text before the first heading always stays in
+// customInstructions, except for the paragraph already used as the roleDefinition.
+func (s *SectionSplitter) Split(body, roleDefinition string) splitResult {
+	var result splitResult
+	var instructions []string
+	size := 0
+
+	for _, section := range splitSections(body) {
+		if section.Heading == "" {
+			if preamble := withoutParagraph(section.Text, roleDefinition); preamble != "" {
+				instructions = append(instructions, preamble)
+				size += len(preamble)
+			}
+			continue
+		}
+
+		target := s.target(section.Heading)
+		if target == TargetInstructions && s.config.MaxInstructionChars > 0 && size+len(section.Text) > s.config.MaxInstructionChars {
+			target = TargetRules
+		}
+
+		switch target {
+		case TargetRole:
+			result.Role = append(result.Role, section.Text)
+		case TargetInstructions:
+			instructions = append(instructions, section.Text)
+			size += len(section.Text)
+		case TargetRules:
+			name := strings.Trim(ruleNameRe.ReplaceAllString(strings.ToLower(section.Heading), "-"), "-")
+			if name == "" {
+				name = "section"
+			}
+			result.RuleFiles = append(result.RuleFiles, RuleFile{
+				Name:    fmt.Sprintf("%02d-%s.md", len(result.RuleFiles)+1, name),
+				Content: section.Text + "\n",
+			})
+		}
+	}
+
+	result.Instructions = strings.Join(instructions, "\n\n")
+	return result
+}
+
+// splitSections divides markdown at # and ## headings outside code fences
+func splitSections(body string) []markdownSection {
+	var sections []markdownSection
+	current := markdownSection{}
+	var lines []string
+	inFence := false
+
+	flush := func() {
+		current.Text = strings.TrimSpace(strings.Join(lines, "\n"))
+		if current.Text != "" {
+			sections = append(sections, current)
+		}
+		lines = nil
+	}
+
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if match := sectionHeadingRe.FindStringSubmatch(line); match != nil && !inFence {
+			flush()
+			current = markdownSection{Heading: match[1]}
+		}
+		lines = append(lines, line)
+	}
+	flush()
+	return sections
+}
+
+// withoutParagraph removes the paragraph whose text matches paragraph, ignoring line breaks
+func withoutParagraph(text, paragraph string) string {
+	if paragraph == "" {
+		return text
+	}
+	var kept []string
+	for _, block := range strings.Split(text, "\n\n") {
+		if collapseWhitespace(block) != paragraph {
+			kept = append(kept, block)
+		}
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n\n"))
+}
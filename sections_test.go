@@ -0,0 +1,170 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const sectionedBody = `You are a backend architect.
+
+Work carefully.
+
+## Focus Areas
+- API design
+
+## Approach
+1. Read the code
+
+` + "```md\n## Not a heading\n```" + `
+
+## Examples
+Example one.
+
+## Changelog
+Old notes.`
+
+func TestSplitSections(t *testing.T) {
+	sections := splitSections(sectionedBody)
+	var headings []string
+	for _, section := range sections {
+		headings = append(headings, section.Heading)
+	}
+	if strings.Join(headings, "|") != "|Focus Areas|Approach|Examples|Changelog" {
+		t.Errorf("Unexpected headings: %q", headings)
+	}
+	if !strings.Contains(sections[2].Text, "## Not a heading") {
+		t.Errorf("Expected fenced heading to stay in its section, got %q", sections[2].Text)
+	}
+}
+
+func TestSectionSplitter_DefaultRules(t *testing.T) {
+	splitter, err := NewSectionSplitter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := splitter.Split(sectionedBody, "You are a backend architect.")
+
+	if len(result.Role) != 1 || !strings.HasPrefix(result.Role[0], "## Focus Areas") {
+		t.Errorf("Expected Focus Areas in role, got %q", result.Role)
+	}
+	if !strings.HasPrefix(result.Instructions, "Work carefully.\n\n## Approach") || !strings.Contains(result.Instructions, "## Changelog") {
+		t.Errorf("Unexpected instructions:\n%s", result.Instructions)
+	}
+	if strings.Contains(result.Instructions, "You are a backend architect") {
+		t.Error("Expected the identity paragraph to be removed from instructions")
+	}
+	if len(result.RuleFiles) != 1 || result.RuleFiles[0].Name != "01-examples.md" {
+		t.Errorf("Unexpected rule files: %+v", result.RuleFiles)
+	}
+}
+
+func TestSectionSplitter_ConfigAndOverflow(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, "split.yaml", `maxInstructionChars: 40
+default: instructions
+rules:
+  - match: changelog
+    target: drop
+`)
+	config, err := LoadSplitConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	splitter, err := NewSectionSplitter(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := splitter.Split(sectionedBody, "")
+
+	// The preamble fills the budget, so every later instruction section overflows
+	var names []string
+	for _, rule := range result.RuleFiles {
+		names = append(names, rule.Name)
+	}
+	if strings.Join(names, ",") != "01-focus-areas.md,02-approach.md,03-examples.md" {
+		t.Errorf("Unexpected rule files: %v", names)
+	}
+	if strings.Contains(result.Instructions, "Changelog") {
+		t.Error("Expected Changelog to be dropped")
+	}
+
+	if _, err := NewSectionSplitter(&SplitConfig{Rules: []SectionRule{{Match: "x", Target: "elsewhere"}}}); err == nil {
+		t.Error("Expected an error for an unknown target")
+	}
+}
+
+func TestSaveModeConfig_WritesRuleFiles(t *testing.T) {
+	dir := t.TempDir()
+	c := NewConverter()
+	modes := []KiloMode{{Slug: "architect", Name: "Architect", RuleFiles: []RuleFile{{Name: "01-examples.md", Content: "## Examples\n"}}}}
+	if _, err := c.saveModeConfig(modes, dir, "custom_modes.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, ".kilocode", "rules-architect", "01-examples.md"))
+	if err != nil || string(content) != "## Examples\n" {
+		t.Errorf("Expected rule file, got %q, %v", content, err)
+	}
+}
+
+func TestInstructionsAsRules(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestFile(t, filepath.Join(dir, "in", "backend"), "api.md", "---\nname: api-designer\ndescription: Designs APIs\n---\nFollow REST conventions.")
+
+	c := NewConverter()
+	c.instructionsAsRules = true
+	mode, err := c.convertAgent(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode.CustomInstructions != "" || len(mode.RuleFiles) != 1 || mode.RuleFiles[0].Name != "instructions.md" {
+		t.Fatalf("Expected instructions moved to a rule file, got %+v", mode)
+	}
+
+	out := filepath.Join(dir, "out")
+	modeFile, err := c.saveSingleModeConfigWithPath(*mode, path, filepath.Join(dir, "in"), out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(modeFile)
+	if strings.Contains(string(data), "customInstructions") {
+		t.Errorf("Expected customInstructions to be omitted:\n%s", data)
+	}
+	rule, err := os.ReadFile(filepath.Join(out, "backend", ".kilocode", "rules-api-designer", "instructions.md"))
+	if err != nil || string(rule) != "Follow REST conventions.\n" {
+		t.Errorf("Expected instructions.md next to the mode file, got %q, %v", rule, err)
+	}
+}
+
+func TestSplitSections_YAMLRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, "architect.md", "---\nname: backend-architect\ndescription: Designs backends\n---\n"+sectionedBody)
+
+	c := NewConverter()
+	var err error
+	if c.splitter, err = NewSectionSplitter(nil); err != nil {
+		t.Fatal(err)
+	}
+	mode, err := c.convertAgent(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(mode.RoleDefinition, "\n## Focus Areas\n- API design") {
+		t.Fatalf("Expected a multi-line roleDefinition, got %q", mode.RoleDefinition)
+	}
+
+	data, err := c.marshalModes(CustomModesFile{CustomModes: []KiloMode{*mode}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded CustomModesFile
+	if err := yaml.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Expected valid YAML, got: %v\n%s", err, data)
+	}
+	if decoded.CustomModes[0].RoleDefinition != mode.RoleDefinition {
+		t.Errorf("Expected the roleDefinition to round-trip, got %q", decoded.CustomModes[0].RoleDefinition)
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Slug length limits; longer slugs are shortened and keep a hash of the full slug so they stay distinct
+const (
+	defaultSlugMaxLength = 64
+	minSlugMaxLength     = 16
+	slugHashLength       = 6
+)
+
+// SlugOptions chooses how mode slugs are generated
+type SlugOptions struct {
+	Strategy  string `yaml:"strategy"`  // name, filename, path or prefix:<namespace>
+	MaxLength int    `yaml:"maxLength"` // Zero uses defaultSlugMaxLength
+}
+
+// validateSlugStrategy checks a slug strategy given by flag or config
+func validateSlugStrategy(strategy string) error {
+	switch {
+	case strategy == "name", strategy == "filename", strategy == "path":
+		return nil
+	case strings.HasPrefix(strategy, "prefix:"):
+		if slugify(strings.TrimPrefix(strategy, "prefix:")) == "" {
+			return fmt.Errorf("slug strategy %q needs a namespace, e.g. prefix:acme", strategy)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown slug strategy %q (use name, filename, path or prefix:<namespace>)", strategy)
+}
+
+// Validate checks options read from flags or a config file
+func (o *SlugOptions) Validate() error {
+	if o.Strategy != "" {
+		if err := validateSlugStrategy(o.Strategy); err != nil {
+			return err
+		}
+	}
+	if o.MaxLength != 0 && o.MaxLength < minSlugMaxLength {
+		return fmt.Errorf("slug max length %d is below the minimum of %d", o.MaxLength, minSlugMaxLength)
+	}
+	return nil
+}
+
+// slugOptionsFor returns the slug options for a source file: the flags when given,
+// otherwise the nearest .claude2kilo.yaml with a slug section
+func (c *Converter) slugOptionsFor(sourcePath string) SlugOptions {
+	options := SlugOptions{Strategy: "name", MaxLength: defaultSlugMaxLength}
+	if config := c.nearestConfig(sourcePath, func(config *sourceConfig) bool { return config.Slug != nil }); config != nil {
+		if config.Slug.Strategy != "" {
+			options.Strategy = config.Slug.Strategy
+		}
+		if config.Slug.MaxLength != 0 {
+			options.MaxLength = config.Slug.MaxLength
+		}
+	}
+	if c.slugStrategy != "" {
+		options.Strategy = c.slugStrategy
+	}
+	if c.slugMaxLength != 0 {
+		options.MaxLength = c.slugMaxLength
+	}
+	return options
+}
+
+// modeSlug generates the slug for an agent with the strategy that applies to its source file.
+// When a file holds several agents, the filename and path strategies add each agent's name.
+func (c *Converter) modeSlug(agent *SourceAgent, shared bool) string {
+	options := c.slugOptionsFor(agent.SourcePath)
+
+	name := c.generateSlug(agent.Slug)
+	if name == "" {
+		name = c.generateSlug(agent.Name)
+	}
+	file := c.generateSlug(strings.TrimSuffix(filepath.Base(agent.SourcePath), filepath.Ext(agent.SourcePath)))
+
+	var slug string
+	switch {
+	case options.Strategy == "filename":
+		slug = joinSlug(file, sharedName(name, shared))
+	case options.Strategy == "path":
+		slug = joinSlug(c.generateSlug(c.sourceRelPath(agent.SourcePath)), sharedName(name, shared))
+	case strings.HasPrefix(options.Strategy, "prefix:"):
+		slug = prefixSlug(c.generateSlug(strings.TrimPrefix(options.Strategy, "prefix:")), name)
+	default:
+		slug = name
+	}
+
+	// Never empty: fall back to the file name, then to a hash of whatever identifies the agent
+	if slug == "" {
+		slug = file
+	}
+	if slug == "" {
+		slug = "mode-" + shortHash(agent.Name+agent.SourcePath)
+	}
+	return limitSlug(slug, options.MaxLength)
+}
+
+// sharedName is the agent's part of a file-based slug, needed only when the file holds several agents
+func sharedName(name string, shared bool) string {
+	if shared {
+		return name
+	}
+	return ""
+}
+
+// sourceRelPath returns a source path relative to the input root without its extension
+func (c *Converter) sourceRelPath(path string) string {
+	rel := filepath.Base(path)
+	if c.inputRoot != "" {
+		if r, err := filepath.Rel(c.inputRoot, path); err == nil && !strings.HasPrefix(r, "..") {
+			rel = r
+		}
+	}
+	return strings.TrimSuffix(rel, filepath.Ext(rel))
+}
+
+// prefixSlug adds a namespace to a slug unless it already has it
+func prefixSlug(prefix, slug string) string {
+	if prefix == "" || strings.HasPrefix(slug, prefix+"-") {
+		return slug
+	}
+	return joinSlug(prefix, slug)
+}
+
+// joinSlug joins the non-empty slug parts with hyphens
+func joinSlug(parts ...string) string {
+	var kept []string
+	for _, part := range parts {
+		if part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, "-")
+}
+
+// limitSlug shortens a slug to maxLength at a word boundary, ending it with a hash of the full slug
+func limitSlug(slug string, maxLength int) string {
+	if maxLength <= 0 {
+		maxLength = defaultSlugMaxLength
+	}
+	if len(slug) <= maxLength {
+		return slug
+	}
+	head := slug[:maxLength-slugHashLength-1]
+	if cut := strings.LastIndex(head, "-"); cut > len(head)/2 {
+		head = head[:cut]
+	}
+	return strings.Trim(head, "-") + "-" + shortHash(slug)
+}
+
+// shortHash returns the first hex digits of a string's SHA-1
+func shortHash(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])[:slugHashLength]
+}
+
+// slugify lowercases text, transliterates it to ASCII and joins its words with hyphens
+func slugify(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range transliterate(strings.ToLower(text)) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimRight(b.String(), "-")
+}
+
+// latinFolds maps accented and ligature letters to their ASCII spelling
+var latinFolds = map[string]string{
+	"a": "àáâãäåāăą", "ae": "æ", "c": "çćĉċč", "d": "ďđð", "e": "èéêëēĕėęě", "g": "ĝğġģ", "h": "ĥħ",
+	"i": "ìíîïĩīĭįı", "j": "ĵ", "k": "ķ", "l": "ĺļľŀł", "n": "ñńņňŉ", "o": "òóôõöøōŏő", "oe": "œ",
+	"r": "ŕŗř", "s": "śŝşšș", "ss": "ß", "t": "ţťŧț", "th": "þ", "u": "ùúûüũūŭůűų", "w": "ŵ",
+	"y": "ýÿŷ", "z": "źżž",
+}
+
+// greekLetters and cyrillicLetters romanize lowercase Greek and Cyrillic
+var greekLetters = map[string]string{
+	"a": "αά", "v": "β", "g": "γ", "d": "δ", "e": "εέ", "z": "ζ", "i": "ηήιίϊΐ", "th": "θ", "k": "κ",
+	"l": "λ", "m": "μ", "n": "ν", "x": "ξ", "o": "οόωώ", "p": "π", "r": "ρ", "s": "σς", "t": "τ",
+	"y": "υύϋΰ", "f": "φ", "ch": "χ", "ps": "ψ",
+}
+
+var cyrillicLetters = map[string]string{
+	"a": "а", "b": "б", "v": "в", "g": "гґ", "d": "д", "e": "еэ", "yo": "ё", "zh": "ж", "z": "з", "i": "иі",
+	"y": "йы", "k": "к", "l": "л", "m": "м", "n": "н", "o": "о", "p": "п", "r": "р", "s": "с", "t": "т",
+	"u": "у", "f": "ф", "kh": "х", "ts": "ц", "ch": "ч", "sh": "ш", "shch": "щ", "": "ъь", "yu": "ю",
+	"ya": "я", "yi": "ї", "ye": "є",
+}
+
+// kanaSyllables romanizes hiragana in gojūon order; katakana is mapped onto it
+var kanaSyllables = strings.Fields(`
+	a a i i u u e e o o ka ga ki gi ku gu ke ge ko go sa za shi ji su zu se ze so zo ta da chi ji
+	tsu tsu zu te de to do na ni nu ne no ha ba pa hi bi pi fu bu pu he be pe ho bo po ma mi mu me mo
+	ya ya yu yu yo yo ra ri ru re ro wa wa i e o n vu`)
+
+// Hangul syllables are romanized by decomposing them (Revised Romanization)
+var (
+	hangulInitials = strings.Split("g,kk,n,d,tt,r,m,b,pp,s,ss,,j,jj,ch,k,t,p,h", ",")
+	hangulMedials  = strings.Split("a,ae,ya,yae,eo,e,yeo,ye,o,wa,wae,oe,yo,u,wo,we,wi,yu,eu,ui,i", ",")
+	hangulFinals   = strings.Split(",k,k,k,n,n,n,t,l,k,m,l,l,l,p,l,m,p,p,t,t,ng,t,t,k,t,p,t", ",")
+)
+
+// transliterations maps single runes to ASCII, built from the tables above
+var transliterations = func() map[rune]string {
+	table := make(map[rune]string)
+	for _, letters := range []map[string]string{latinFolds, greekLetters, cyrillicLetters} {
+		for ascii, runes := range letters {
+			for _, r := range runes {
+				table[r] = ascii
+			}
+		}
+	}
+	return table
+}()
+
+// transliterate spells lowercase Latin, Greek, Cyrillic, Hangul and kana text in ASCII.
+// Other scripts, such as CJK ideographs, are dropped.
+func transliterate(text string) string {
+	var b strings.Builder
+	double := false // After a small tsu the next consonant is doubled
+	for _, r := range text {
+		ascii, known := "", true
+		switch {
+		case r < 0x80:
+			b.WriteRune(r)
+			continue
+		case r >= 0xAC00 && r <= 0xD7A3:
+			s := int(r - 0xAC00)
+			ascii = hangulInitials[s/588] + hangulMedials[s%588/28] + hangulFinals[s%28]
+		case r == 'っ' || r == 'ッ':
+			double = true
+			continue
+		case r == 'ゃ' || r == 'ゅ' || r == 'ょ' || r == 'ャ' || r == 'ュ' || r == 'ョ':
+			b.WriteString(contractKana(&b, r))
+			continue
+		case r >= 'ぁ' && r <= 'ゔ':
+			ascii = kanaSyllables[r-'ぁ']
+		case r >= 'ァ' && r <= 'ヴ':
+			ascii = kanaSyllables[r-'ァ']
+		case r == 'ー':
+			continue
+		default:
+			ascii, known = transliterations[r]
+		}
+		if double && ascii != "" && !strings.ContainsRune("aeioun", rune(ascii[0])) {
+			b.WriteByte(ascii[0])
+		}
+		double = false
+		if !known && b.Len() > 0 {
+			b.WriteByte(' ') // Untransliterated letters still separate words
+		}
+		b.WriteString(ascii)
+	}
+	return b.String()
+}
+
+// contractKana merges a small ya, yu or yo into the syllable before it ("ki" + "ゃ" is "kya",
+// "shi" + "ゃ" is "sha") and returns the vowel to write
+func contractKana(b *strings.Builder, r rune) string {
+	vowel := map[rune]string{'ゃ': "a", 'ゅ': "u", 'ょ': "o", 'ャ': "a", 'ュ': "u", 'ョ': "o"}[r]
+	written := b.String()
+	if !strings.HasSuffix(written, "i") {
+		return "y" + vowel
+	}
+	written = strings.TrimSuffix(written, "i")
+	if !strings.HasSuffix(written, "sh") && !strings.HasSuffix(written, "ch") && !strings.HasSuffix(written, "j") {
+		written += "y"
+	}
+	b.Reset()
+	b.WriteString(written)
+	return vowel
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSlugify_Transliteration(t *testing.T) {
+	cases := map[string]string{
+		"ingénieur-données":  "ingenieur-donnees",
+		"Straße Planer":      "strasse-planer",
+		"데이터-엔지니어":           "deiteo-enjinieo",
+		"Аналитик данных":    "analitik-dannykh",
+		"Μηχανικός":          "michanikos",
+		"データ エンジニア":          "deta-enjinia",
+		"きょう":                "kyou",
+		"数据 reviewer":        "reviewer",
+		"C++ / Go — Expert!": "c-go-expert",
+	}
+	for name, want := range cases {
+		if got := slugify(name); got != want {
+			t.Errorf("slugify(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestModeSlug_Strategies(t *testing.T) {
+	root := t.TempDir()
+	c := NewConverter()
+	c.inputRoot = root
+	agent := &SourceAgent{Name: "python-pro", SourcePath: filepath.Join(root, "community", "pack-a", "python.md")}
+
+	cases := map[string]string{
+		"name":        "python-pro",
+		"filename":    "python",
+		"path":        "community-pack-a-python",
+		"prefix:Acme": "acme-python-pro",
+	}
+	for strategy, want := range cases {
+		c.slugStrategy = strategy
+		if got := c.modeSlug(agent, false); got != want {
+			t.Errorf("%s: got %q, want %q", strategy, got, want)
+		}
+	}
+
+	// A file holding several agents keeps them apart under file-based strategies
+	c.slugStrategy = "filename"
+	if got := c.modeSlug(agent, true); got != "python-python-pro" {
+		t.Errorf("Expected the agent name after the file name, got %q", got)
+	}
+
+	if err := (&SlugOptions{Strategy: "prefix:"}).Validate(); err == nil {
+		t.Error("Expected a prefix without a namespace to be rejected")
+	}
+	if err := (&SlugOptions{Strategy: "uuid"}).Validate(); err == nil {
+		t.Error("Expected an unknown strategy to be rejected")
+	}
+}
+
+func TestModeSlug_NeverEmpty(t *testing.T) {
+	c := NewConverter()
+	if got := c.modeSlug(&SourceAgent{Name: "数据工程师", SourcePath: "agents/data-engineer.md"}, false); got != "data-engineer" {
+		t.Errorf("Expected the file name when the name has no ASCII spelling, got %q", got)
+	}
+	got := c.modeSlug(&SourceAgent{Name: "数据工程师", SourcePath: "agents/数据.md"}, false)
+	if !strings.HasPrefix(got, "mode-") || len(got) != len("mode-")+slugHashLength {
+		t.Errorf("Expected a hashed fallback slug, got %q", got)
+	}
+}
+
+func TestLimitSlug(t *testing.T) {
+	long := "senior-distributed-systems-reliability-engineer-for-payments"
+	got := limitSlug(long, 32)
+	if len(got) > 32 || !strings.HasPrefix(got, "senior-distributed-") || strings.Contains(got, "-system-") {
+		t.Errorf("Expected a shortened slug cut at a word, got %q", got)
+	}
+	if other := limitSlug(long+"-platform", 32); other == got {
+		t.Errorf("Expected shortened slugs to stay distinct, both are %q", got)
+	}
+	if got := limitSlug("short", 32); got != "short" {
+		t.Errorf("Expected a short slug unchanged, got %q", got)
+	}
+}
+
+func TestConvertDirectory_SlugConfig(t *testing.T) {
+	input := t.TempDir()
+	files := map[string]string{
+		"pack-a/.claude2kilo.yaml": "slug:\n  strategy: prefix:pack-a\n",
+		"pack-a/agents/python.md":  "---\nname: python-pro\ndescription: Writes Python\n---\nYou write Python.\n",
+		"pack-b/python.md":         "---\nname: python-pro\ndescription: Writes Python\n---\nYou write Python.\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(input, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	output := t.TempDir()
+	c := NewConverter()
+	if err := c.convertDirectory(input, output, false, false); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(output, "custom_modes.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"slug: pack-a-python-pro", "slug: python-pro"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %q in:\n%s", want, data)
+		}
+	}
+}
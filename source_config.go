@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// sourceConfigName is the per-directory config file; the nearest one above a source file applies
+const sourceConfigName = ".claude2kilo.yaml"
+
+// sourceConfig is the content of a .claude2kilo.yaml file. Flags override it.
+type sourceConfig struct {
+	Slug  *SlugOptions `yaml:"slug"`
+	Names *NameOptions `yaml:"names"`
+}
+
+// nearestConfig walks up from a source file to the input root and returns the first
+// config file with the section wanted reports, or nil
+func (c *Converter) nearestConfig(sourcePath string, wanted func(*sourceConfig) bool) *sourceConfig {
+	if sourcePath == "" {
+		return nil
+	}
+	if c.sourceConfigs == nil {
+		c.sourceConfigs = make(map[string]*sourceConfig)
+	}
+	dir := filepath.Dir(sourcePath)
+	for {
+		config, seen := c.sourceConfigs[dir]
+		if !seen {
+			config = loadSourceConfig(filepath.Join(dir, sourceConfigName))
+			c.sourceConfigs[dir] = config
+		}
+		if config != nil && wanted(config) {
+			return config
+		}
+		parent := filepath.Dir(dir)
+		if c.inputRoot == "" || !withinDir(parent, c.inputRoot) && parent != filepath.Clean(c.inputRoot) || parent == dir {
+			return nil
+		}
+		dir = parent
+	}
+}
+
+// loadSourceConfig reads a config file; a broken file is reported and ignored
+func loadSourceConfig(path string) *sourceConfig {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var config sourceConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		fmt.Printf("⚠ Ignoring %s: %v\n", path, err)
+		return nil
+	}
+	if config.Slug != nil {
+		if err := config.Slug.Validate(); err != nil {
+			fmt.Printf("⚠ Ignoring %s: %v\n", path, err)
+			return nil
+		}
+	}
+	return &config
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// claudeToolGroups maps Claude Code tools to the Kilo group that provides them.
+// An empty group means Kilo offers the capability in every mode.
+var claudeToolGroups = map[string]string{
+	"Read":         "read",
+	"Grep":         "read",
+	"Glob":         "read",
+	"LS":           "read",
+	"NotebookRead": "read",
+	"Write":        "edit",
+	"Edit":         "edit",
+	"MultiEdit":    "edit",
+	"NotebookEdit": "edit",
+	"Bash":         "command",
+	"BashOutput":   "command",
+	"KillShell":    "command",
+	"WebFetch":     "browser",
+	"WebSearch":    "browser",
+	"TodoWrite":    "",
+	"Task":         "",
+}
+
+// ConversionLoss records one piece of information dropped or guessed during conversion
+type ConversionLoss struct {
+	Field string
+	Cause string
+}
+
+// StrictModeError is returned in strict mode when a conversion would lose information
+type StrictModeError struct {
+	Slug   string
+	Losses []ConversionLoss
+}
+
+func (e *StrictModeError) Error() string {
+	parts := make([]string, len(e.Losses))
+	for i, loss := range e.Losses {
+		parts[i] = fmt.Sprintf("%s: %s", loss.Field, loss.Cause)
+	}
+	return fmt.Sprintf("strict mode: lossy conversion of %s: %s", e.Slug, strings.Join(parts, "; "))
+}
+
+// toolLosses reports Claude tools that the mode's groups do not provide
+func toolLosses(tools, groups []string) []ConversionLoss {
+	granted := make(map[string]bool)
+	for _, group := range groups {
+		granted[group] = true
+	}
+
+	var losses []ConversionLoss
+	for _, tool := range tools {
+		tool = strings.TrimSpace(tool)
+		group, known := claudeToolGroups[tool]
+		if strings.HasPrefix(tool, "mcp__") {
+			group, known = "mcp", true
+		}
+		switch {
+		case !known:
+			losses = append(losses, ConversionLoss{"tools", fmt.Sprintf("%s has no Kilo group equivalent", tool)})
+		case group != "" && !granted[group]:
+			losses = append(losses, ConversionLoss{"tools", fmt.Sprintf("%s needs the %s group, which was not granted", tool, group)})
+		}
+	}
+	return losses
+}
+
+// conversionLosses lists what a converted mode dropped or guessed relative to its source agent
+func (c *Converter) conversionLosses(agent *SourceAgent, mode *KiloMode) []ConversionLoss {
+	var losses []ConversionLoss
+
+	if agent.Format == "claude" {
+		losses = append(losses, toolLosses(agent.Tools, mode.Groups)...)
+	}
+
+	if agent.Model != "" && agent.Model != "inherit" && mode.XClaude == nil && c.claudeExtras != "sidecar" {
+		losses = append(losses, ConversionLoss{"model", fmt.Sprintf("%q has no Kilo equivalent (keep it with -claude-extras)", agent.Model)})
+	}
+
+	var extras []string
+	for key := range agent.Extras {
+		if key != "model" {
+			extras = append(extras, key)
+		}
+	}
+	if len(extras) > 0 && c.claudeExtras == "none" {
+		sort.Strings(extras)
+		losses = append(losses, ConversionLoss{"frontmatter", fmt.Sprintf("%s dropped (keep them with -claude-extras)", strings.Join(extras, ", "))})
+	}
+
+	if agent.Sanitization != nil {
+		for _, key := range agent.Sanitization.Keys {
+			losses = append(losses, ConversionLoss{key, "rewritten by YAML sanitization"})
+		}
+	}
+
+	return losses
+}
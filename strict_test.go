@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestToolLosses(t *testing.T) {
+	losses := toolLosses([]string{"Read", "Bash", "TodoWrite", "mcp__github__search", "Frobnicate"}, []string{"read", "edit"})
+	if len(losses) != 3 {
+		t.Fatalf("Expected 3 losses, got %+v", losses)
+	}
+	if !strings.Contains(losses[0].Cause, "Bash needs the command group") {
+		t.Errorf("Unexpected first loss: %+v", losses[0])
+	}
+	if !strings.Contains(losses[2].Cause, "Frobnicate has no Kilo group") {
+		t.Errorf("Unexpected last loss: %+v", losses[2])
+	}
+}
+
+func TestStrictMode_RejectsLossyConversion(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, "agent.md", `---
+name: frontend-developer
+description: Build React components
+model: sonnet
+tools: [Read, Edit, Bash]
+---
+Build UI.`)
+
+	c := NewConverter()
+	c.strict = true
+	_, err := c.convertAgent(path)
+	var strictErr *StrictModeError
+	if !errors.As(err, &strictErr) {
+		t.Fatalf("Expected StrictModeError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "model:") {
+		t.Errorf("Expected model loss in %q", err.Error())
+	}
+
+	// Keeping the model as an extra makes the conversion lossless
+	c.claudeExtras = "inline"
+	if _, err := c.convertAgent(path); err != nil {
+		t.Errorf("Expected lossless conversion, got %v", err)
+	}
+}
+
+func TestStrictMode_RejectsFallbacks(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, "agent.md", "---\nname: zzz\ndescription: qqq\n---\n")
+
+	c := NewConverter()
+	mode, err := c.convertAgent(path)
+	if err != nil {
+		t.Fatalf("Expected non-strict conversion to succeed, got %v", err)
+	}
+	fields := make(map[string]bool)
+	for _, loss := range mode.Losses {
+		fields[loss.Field] = true
+	}
+	for _, field := range []string{"iconName", "description", "whenToUse"} {
+		if !fields[field] {
+			t.Errorf("Expected %s fallback to be recorded, got %+v", field, mode.Losses)
+		}
+	}
+
+	c.strict = true
+	if _, err := c.convertAgent(path); err == nil {
+		t.Error("Expected strict mode to reject heuristic fallbacks")
+	}
+}
+
+func TestStrictMode_SanitizedDescription(t *testing.T) {
+	c := NewConverter()
+	input := "name: a\ndescription: Use this agent when: " + strings.Repeat("x", 200) + ` "quoted" <example>Context: e</example>`
+	record, err := c.decodeFrontmatter(&frontmatterBlock{Content: input, StartLine: 2}, &ClaudeAgent{})
+	if err != nil {
+		t.Fatalf("Expected sanitization to succeed, got %v", err)
+	}
+	if record == nil || len(record.Keys) != 1 || record.Keys[0] != "description" {
+		t.Errorf("Expected description to be recorded as rewritten, got %+v", record)
+	}
+}
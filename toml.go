@@ -0,0 +1,445 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tomlError is a TOML syntax error at a 1-based line and column of the parsed text
+type tomlError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *tomlError) Error() string {
+	return fmt.Sprintf("toml: line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+// tomlParser is a small recursive-descent parser for the TOML used in frontmatter:
+// key/value pairs, strings of every flavour, numbers, booleans, arrays, inline
+// tables and [table] / [[array]] headers. Dates are kept as strings.
+type tomlParser struct {
+	src []rune
+	pos int
+}
+
+// parseTOML parses TOML frontmatter into a generic map
+func parseTOML(content string) (map[string]interface{}, error) {
+	p := &tomlParser{src: []rune(content)}
+	root := make(map[string]interface{})
+	current := root
+
+	for {
+		p.skipBlank(true)
+		if p.eof() {
+			return root, nil
+		}
+
+		if p.peek() == '[' {
+			table, err := p.parseTableHeader(root)
+			if err != nil {
+				return nil, err
+			}
+			current = table
+		} else {
+			if err := p.parseKeyValue(current); err != nil {
+				return nil, err
+			}
+		}
+
+		// Only whitespace or a comment may follow on the same line
+		p.skipBlank(false)
+		if !p.eof() && p.peek() != '\n' {
+			return nil, p.errorf("unexpected %q after value", p.peek())
+		}
+	}
+}
+
+func (p *tomlParser) eof() bool  { return p.pos >= len(p.src) }
+func (p *tomlParser) peek() rune { return p.src[p.pos] }
+
+// lookingAt reports whether the input continues with s
+func (p *tomlParser) lookingAt(s string) bool {
+	return strings.HasPrefix(string(p.src[p.pos:min(len(p.src), p.pos+len(s))]), s)
+}
+
+// errorf builds an error at the current position
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	line, column := 1, 1
+	for _, r := range p.src[:min(p.pos, len(p.src))] {
+		if r == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	return &tomlError{Line: line, Column: column, Message: fmt.Sprintf(format, args...)}
+}
+
+// skipBlank skips spaces, tabs and comments, and newlines too when multiline is set
+func (p *tomlParser) skipBlank(multiline bool) {
+	for !p.eof() {
+		switch r := p.peek(); {
+		case r == ' ' || r == '\t' || r == '\r' || (multiline && r == '\n'):
+			p.pos++
+		case r == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// parseTableHeader handles [a.b] and [[a.b]] lines, returning the table to fill
+func (p *tomlParser) parseTableHeader(root map[string]interface{}) (map[string]interface{}, error) {
+	isArray := p.lookingAt("[[")
+	if isArray {
+		p.pos += 2
+	} else {
+		p.pos++
+	}
+
+	path, err := p.parseKeyPath()
+	if err != nil {
+		return nil, err
+	}
+	closing := "]"
+	if isArray {
+		closing = "]]"
+	}
+	p.skipBlank(false)
+	if !p.lookingAt(closing) {
+		return nil, p.errorf("expected %s to close table header", closing)
+	}
+	p.pos += len(closing)
+
+	parent, err := p.descend(root, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+	table := make(map[string]interface{})
+
+	if isArray {
+		list, _ := parent[last].([]interface{})
+		parent[last] = append(list, table)
+		return table, nil
+	}
+	if existing, ok := parent[last].(map[string]interface{}); ok {
+		return existing, nil
+	}
+	if _, exists := parent[last]; exists {
+		return nil, p.errorf("key %q is already defined", last)
+	}
+	parent[last] = table
+	return table, nil
+}
+
+// descend walks (and creates) nested tables along a dotted key path
+func (p *tomlParser) descend(table map[string]interface{}, path []string) (map[string]interface{}, error) {
+	for _, key := range path {
+		switch next := table[key].(type) {
+		case nil:
+			child := make(map[string]interface{})
+			table[key] = child
+			table = child
+		case map[string]interface{}:
+			table = next
+		case []interface{}:
+			// [[array]] tables: keys refer to the most recent element
+			child, ok := next[len(next)-1].(map[string]interface{})
+			if !ok {
+				return nil, p.errorf("key %q is not a table", key)
+			}
+			table = child
+		default:
+			return nil, p.errorf("key %q is not a table", key)
+		}
+	}
+	return table, nil
+}
+
+// parseKeyValue parses key = value into table
+func (p *tomlParser) parseKeyValue(table map[string]interface{}) error {
+	path, err := p.parseKeyPath()
+	if err != nil {
+		return err
+	}
+	p.skipBlank(false)
+	if p.eof() || p.peek() != '=' {
+		return p.errorf("expected = after key %q", strings.Join(path, "."))
+	}
+	p.pos++
+	p.skipBlank(false)
+
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+
+	parent, err := p.descend(table, path[:len(path)-1])
+	if err != nil {
+		return err
+	}
+	last := path[len(path)-1]
+	if _, exists := parent[last]; exists {
+		return p.errorf("key %q is defined twice", last)
+	}
+	parent[last] = value
+	return nil
+}
+
+// parseKeyPath parses a bare, quoted or dotted key
+func (p *tomlParser) parseKeyPath() ([]string, error) {
+	var path []string
+	for {
+		p.skipBlank(false)
+		if p.eof() {
+			return nil, p.errorf("expected a key")
+		}
+
+		var key string
+		switch r := p.peek(); {
+		case r == '"' || r == '\'':
+			value, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+			key = value
+		case r == '_' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			start := p.pos
+			for !p.eof() && (p.peek() == '_' || p.peek() == '-' || unicode.IsLetter(p.peek()) || unicode.IsDigit(p.peek())) {
+				p.pos++
+			}
+			key = string(p.src[start:p.pos])
+		default:
+			return nil, p.errorf("unexpected %q where a key was expected", r)
+		}
+		path = append(path, key)
+
+		p.skipBlank(false)
+		if p.eof() || p.peek() != '.' {
+			return path, nil
+		}
+		p.pos++
+	}
+}
+
+// parseValue parses any TOML value
+func (p *tomlParser) parseValue() (interface{}, error) {
+	if p.eof() || p.peek() == '\n' {
+		return nil, p.errorf("expected a value")
+	}
+
+	switch r := p.peek(); {
+	case r == '"' || r == '\'':
+		return p.parseString()
+	case r == '[':
+		return p.parseArray()
+	case r == '{':
+		return p.parseInlineTable()
+	case p.lookingAt("true"):
+		p.pos += 4
+		return true, nil
+	case p.lookingAt("false"):
+		p.pos += 5
+		return false, nil
+	case r == '+' || r == '-' || unicode.IsDigit(r):
+		return p.parseNumberOrDate()
+	default:
+		return nil, p.errorf("unexpected %q where a value was expected", r)
+	}
+}
+
+// parseString parses basic, literal and multi-line strings
+func (p *tomlParser) parseString() (string, error) {
+	quote := p.peek()
+	multiline := p.lookingAt(strings.Repeat(string(quote), 3))
+	delimiter := string(quote)
+	if multiline {
+		delimiter = strings.Repeat(delimiter, 3)
+	}
+	p.pos += len(delimiter)
+
+	// A newline right after an opening """ or ''' is not part of the string
+	if multiline && !p.eof() && p.peek() == '\n' {
+		p.pos++
+	}
+
+	var value strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		if p.lookingAt(delimiter) {
+			p.pos += len(delimiter)
+			return value.String(), nil
+		}
+
+		r := p.peek()
+		if r == '\n' && !multiline {
+			return "", p.errorf("newline in single-line string")
+		}
+		if r == '\\' && quote == '"' {
+			if err := p.parseEscape(&value, multiline); err != nil {
+				return "", err
+			}
+			continue
+		}
+		value.WriteRune(r)
+		p.pos++
+	}
+}
+
+// parseEscape decodes one backslash escape in a basic string
+func (p *tomlParser) parseEscape(value *strings.Builder, multiline bool) error {
+	p.pos++
+	if p.eof() {
+		return p.errorf("unterminated escape")
+	}
+	r := p.peek()
+	p.pos++
+
+	switch r {
+	case 'n':
+		value.WriteRune('\n')
+	case 't':
+		value.WriteRune('\t')
+	case 'r':
+		value.WriteRune('\r')
+	case 'b':
+		value.WriteRune('\b')
+	case 'f':
+		value.WriteRune('\f')
+	case '"', '\\':
+		value.WriteRune(r)
+	case 'u', 'U':
+		size := 4
+		if r == 'U' {
+			size = 8
+		}
+		if p.pos+size > len(p.src) {
+			return p.errorf("short unicode escape")
+		}
+		code, err := strconv.ParseUint(string(p.src[p.pos:p.pos+size]), 16, 32)
+		if err != nil {
+			return p.errorf("invalid unicode escape")
+		}
+		value.WriteRune(rune(code))
+		p.pos += size
+	case ' ', '\t', '\n':
+		if !multiline {
+			return p.errorf("invalid escape \\%c", r)
+		}
+		// Line-ending backslash: trim whitespace up to the next content
+		for !p.eof() && unicode.IsSpace(p.peek()) {
+			p.pos++
+		}
+	default:
+		return p.errorf("invalid escape \\%c", r)
+	}
+	return nil
+}
+
+// parseArray parses [a, b, ...], which may span lines
+func (p *tomlParser) parseArray() ([]interface{}, error) {
+	p.pos++
+	values := []interface{}{}
+	for {
+		p.skipBlank(true)
+		if p.eof() {
+			return nil, p.errorf("unterminated array")
+		}
+		if p.peek() == ']' {
+			p.pos++
+			return values, nil
+		}
+
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		p.skipBlank(true)
+		if p.eof() {
+			return nil, p.errorf("unterminated array")
+		}
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return nil, p.errorf("expected , or ] in array")
+		}
+	}
+}
+
+// parseInlineTable parses {key = value, ...}
+func (p *tomlParser) parseInlineTable() (map[string]interface{}, error) {
+	p.pos++
+	table := make(map[string]interface{})
+	for {
+		p.skipBlank(false)
+		if p.eof() {
+			return nil, p.errorf("unterminated inline table")
+		}
+		if p.peek() == '}' {
+			p.pos++
+			return table, nil
+		}
+
+		if err := p.parseKeyValue(table); err != nil {
+			return nil, err
+		}
+
+		p.skipBlank(false)
+		if p.eof() {
+			return nil, p.errorf("unterminated inline table")
+		}
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+		default:
+			return nil, p.errorf("expected , or } in inline table")
+		}
+	}
+}
+
+// parseNumberOrDate parses integers and floats; dates and times are returned as strings
+func (p *tomlParser) parseNumberOrDate() (interface{}, error) {
+	start := p.pos
+	for !p.eof() && strings.ContainsRune("+-_.:0123456789abcdefABCDEFxoTZ", p.peek()) {
+		p.pos++
+	}
+	// Local date-times may separate date and time with a space
+	if !p.eof() && p.peek() == ' ' && p.pos+1 < len(p.src) && unicode.IsDigit(p.src[p.pos+1]) &&
+		strings.Count(string(p.src[start:p.pos]), "-") == 2 {
+		p.pos++
+		for !p.eof() && strings.ContainsRune("+-.:0123456789Z", p.peek()) {
+			p.pos++
+		}
+	}
+	token := string(p.src[start:p.pos])
+
+	if strings.Contains(token, ":") || strings.Count(token, "-") >= 2 && !strings.ContainsAny(token, "eE") {
+		return token, nil
+	}
+
+	clean := strings.ReplaceAll(token, "_", "")
+	if i, err := strconv.ParseInt(clean, 0, 64); err == nil {
+		return int(i), nil
+	}
+	if f, err := strconv.ParseFloat(clean, 64); err == nil {
+		return f, nil
+	}
+	p.pos = start
+	return nil, p.errorf("invalid number %q", token)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	input := `# comment
+name = "a \"b\"\tc" # trailing
+path = 'C:\raw'
+count = 1_000
+ratio = 0.5
+enabled = true
+created = 2024-01-02
+tools = [
+  "Read",
+  "Edit", # last
+]
+text = """
+line one
+line two"""
+point = { x = 1, y = 2 }
+a.b = "dotted"
+
+[settings]
+mode = "fast"
+
+[[hooks]]
+event = "start"
+[[hooks]]
+event = "stop"
+`
+	got, err := parseTOML(input)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	want := map[string]interface{}{
+		"name":     "a \"b\"\tc",
+		"path":     `C:\raw`,
+		"count":    1000,
+		"ratio":    0.5,
+		"enabled":  true,
+		"created":  "2024-01-02",
+		"tools":    []interface{}{"Read", "Edit"},
+		"text":     "line one\nline two",
+		"point":    map[string]interface{}{"x": 1, "y": 2},
+		"a":        map[string]interface{}{"b": "dotted"},
+		"settings": map[string]interface{}{"mode": "fast"},
+		"hooks": []interface{}{
+			map[string]interface{}{"event": "start"},
+			map[string]interface{}{"event": "stop"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected result:\n got: %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestParseTOML_Errors(t *testing.T) {
+	cases := []struct {
+		input  string
+		line   int
+		column int
+	}{
+		{"name = \"unterminated", 1, 21},
+		{"a = 1\na = 2", 2, 6},
+		{"a = [1, 2", 1, 10},
+		{"a = 1 b = 2", 1, 7},
+		{"= 1", 1, 1},
+	}
+	for _, tc := range cases {
+		_, err := parseTOML(tc.input)
+		var tomlErr *tomlError
+		if !errors.As(err, &tomlErr) {
+			t.Fatalf("%q: expected tomlError, got %v", tc.input, err)
+		}
+		if tomlErr.Line != tc.line || tomlErr.Column != tc.column {
+			t.Errorf("%q: expected error at %d:%d, got %v", tc.input, tc.line, tc.column, err)
+		}
+	}
+}
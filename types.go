@@ -10,26 +10,77 @@ type ClaudeAgent struct {
 	Description string   `yaml:"description"`
 	Model       string   `yaml:"model"`
 	Tools       []string `yaml:"tools,omitempty"`
+
+	// Extras captures frontmatter keys (e.g. color) that have no dedicated field
+	Extras map[string]interface{} `yaml:",inline"`
 }
 
-// KiloMode represents a Kilo Code mode configuration
+// KiloMode represents a Kilo Code mode configuration. It is written and read through
+// kiloModeOutput, which names the serialized fields; the rest are conversion state.
 type KiloMode struct {
-	Slug               string   `yaml:"slug"`
-	Name               string   `yaml:"name"`
-	IconName           string   `yaml:"iconName"` // NEW FIELD
-	RoleDefinition     string   `yaml:"roleDefinition"`
-	WhenToUse          string   `yaml:"whenToUse,omitempty"`
-	Description        string   `yaml:"description"` // NEW FIELD (now included in YAML)
-	Groups             []string `yaml:"groups"`
-	CustomInstructions string   `yaml:"customInstructions"`
-	Source             string   `yaml:"source"`
-	FileRegex          string   `yaml:"-"` // Not included in YAML output
-	OriginalModel      string   `yaml:"-"` // Not included in YAML output
+	Slug                 string
+	Name                 string
+	IconName             string // NEW FIELD
+	RoleDefinition       string
+	WhenToUse            string
+	Description          string // NEW FIELD (now included in YAML)
+	Groups               []string
+	CustomInstructions   string
+	Source               string
+	FileRegex            string // Written as options of the edit group
+	FileRegexDescription string
+	OriginalModel        string // Not included in output
+	Plugin               string // Claude Code plugin the mode was imported from
+	SourceFile           string // Source path relative to the input directory, for -catalog
+
+	// XClaude carries Claude frontmatter without a Kilo equivalent when -claude-extras=inline
+	XClaude      map[string]interface{}
+	ClaudeExtras map[string]interface{}
+	Losses       []ConversionLoss // Information dropped or guessed during conversion
+	Examples     []AgentExample   // <example> blocks extracted from the description
+	RuleFiles    []RuleFile       // Sections written to .kilocode/rules-<slug>/
+	Traces       []DecisionTrace  // Why each heuristic field got its value
+
+	// IconCandidates are the icons the mode could use, chosen icon first, for -unique-icons
+	IconCandidates []CandidateScore
+	// Violations are the -policy rules that warned about or downgraded the mode
+	Violations []PolicyViolation
 }
 
 // CustomModesFile represents the root structure for Kilo Code custom modes
 type CustomModesFile struct {
-	CustomModes []KiloMode `yaml:"customModes"`
+	CustomModes []KiloMode `yaml:"customModes" json:"customModes"`
+}
+
+// SourceAgent is the format-neutral agent model produced by a Reader
+type SourceAgent struct {
+	Name         string // Identifier used for slug generation and content analysis
+	Description  string
+	Model        string
+	Tools        []string
+	Body         string // Markdown prompt body
+	Format       string // Name of the reader that produced the agent
+	SourcePath   string
+	Sanitization *SanitizationRecord    // Set when the frontmatter needed YAML sanitization
+	Extras       map[string]interface{} // Source frontmatter keys with no Kilo equivalent
+
+	// Optional fields for source formats that already carry a Kilo equivalent
+	Slug                 string
+	DisplayName          string
+	IconName             string
+	RoleDefinition       string
+	WhenToUse            string
+	Summary              string
+	Groups               []string
+	FileRegex            string
+	FileRegexDescription string
+}
+
+// Reader parses agent definitions from one source ecosystem into SourceAgents
+type Reader interface {
+	Name() string
+	Detect(path string, content []byte) bool
+	Read(path string, content []byte) ([]*SourceAgent, error)
 }
 
 // IconSelector handles intelligent icon selection
@@ -39,6 +90,8 @@ type IconSelector struct {
 	characteristicKeywords map[string]string
 	fallbackMap            map[string]string
 	validIcons             map[string]bool
+	patterns               []iconPattern  // From -icon-rules; match the agent's name as written
+	keywordWeights         map[string]int // Multipliers for configured domain keywords
 }
 
 // ContentAnalyzer handles intelligent content analysis for generating "when to use" statements
@@ -52,10 +105,31 @@ type ContentAnalyzer struct {
 // Converter handles the conversion logic
 type Converter struct {
 	modelMapping    map[string]string
-	defaultGroups   map[string][]string
 	frontmatterRe   *regexp.Regexp
-	slugRe          *regexp.Regexp
 	iconSelector    *IconSelector
 	contentAnalyzer *ContentAnalyzer
 	yamlSanitizer   *YAMLSanitizer
+	splitter        *SectionSplitter         // Nil unless prompt bodies are split by section
+	classifier      *ClassifierModel         // Nil unless -classifier is set; keyword rules decide alone
+	enricher        Enricher                 // Nil unless -enrich is set; heuristics decide alone
+	iconIssues      []FileIssue              // Unknown icons in -icon-rules, added to the diagnostic report
+	uniqueIcons     bool                     // Reassign shared icons across the converted set
+	maxGroups       []string                 // Nil allows every group; set by -max-groups
+	policy          *Policy                  // Guardrails from -policy, applied to every converted mode
+	slugStrategy    string                   // Set by -slug; overrides .claude2kilo.yaml files
+	slugMaxLength   int                      // Set by -slug-max-length; overrides .claude2kilo.yaml files
+	sourceConfigs   map[string]*sourceConfig // .claude2kilo.yaml files by directory; nil entries have none
+	nameOptions     NameOptions              // Set by -acronyms and -name-emoji; added to .claude2kilo.yaml files
+	catalog         []string                 // Formats written by -catalog: html, markdown
+	plugins         []*pluginInfo            // Plugins found in the input directory, whose modes are namespaced
+	inputRoot       string                   // Directory being converted, for the path slug strategy
+	readers         []Reader
+
+	// Options set from command line flags
+	outputFormat        string
+	claudeExtras        string
+	examples            string // Where extracted <example> blocks go: when-to-use or instructions
+	strict              bool
+	showSanitization    bool
+	instructionsAsRules bool // Write customInstructions to .kilocode/rules-<slug>/instructions.md
 }
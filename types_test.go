@@ -3,6 +3,8 @@ package main
 import (
 	"reflect"
 	"testing"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestClaudeAgentFields(t *testing.T) {
@@ -53,3 +55,17 @@ func TestConverterStruct(t *testing.T) {
 		t.Error("Converter struct not instantiable")
 	}
 }
+
+func TestClaudeAgentExtras(t *testing.T) {
+	var agent ClaudeAgent
+	input := "name: n\ndescription: d\ncolor: blue\npriority: 3\n"
+	if err := yaml.Unmarshal([]byte(input), &agent); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if agent.Extras["color"] != "blue" || agent.Extras["priority"] != 3 {
+		t.Errorf("Expected unknown keys in Extras, got %+v", agent.Extras)
+	}
+	if _, ok := agent.Extras["name"]; ok {
+		t.Error("Expected known keys to stay out of Extras")
+	}
+}
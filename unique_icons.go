@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// deliberateIconScore outranks any keyword score, so icons set by the source, an exact
+// role, an icon pattern or the classifier are only given up to an earlier deliberate claim
+const deliberateIconScore = 1 << 20
+
+// IconDuplicate is an icon that several modes still share after -unique-icons
+type IconDuplicate struct {
+	Icon  string   `json:"icon"`
+	Modes []string `json:"modes"`
+}
+
+// iconCandidates lists the icons a mode could use, its chosen icon first. Keyword-scored
+// choices keep their scores; runners-up come from the same keyword scoring.
+func (c *Converter) iconCandidates(trace *DecisionTrace, text *AnalysisText) []CandidateScore {
+	switch {
+	case trace.Fallback != "":
+		// Nothing matched, so any keyword-chosen mode has a better claim
+		return []CandidateScore{{Value: trace.Value}}
+	case strings.HasPrefix(trace.Rule, keywordScoreRule):
+		return trace.Candidates
+	}
+
+	candidates := []CandidateScore{{Value: trace.Value, Score: deliberateIconScore}}
+	for _, candidate := range c.iconSelector.scoreIcons(text) {
+		if candidate.Value != trace.Value {
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates
+}
+
+// assignUniqueIcons gives every icon to the mode with the strongest claim on it and moves
+// the others to their next-best unused candidate. Modes that run out of candidates keep
+// their icon; the icons they still share are returned.
+func assignUniqueIcons(modes []*KiloMode) []IconDuplicate {
+	type claim struct {
+		mode, rank int
+		icon       string
+		score      int
+	}
+	var claims []claim
+	for i, mode := range modes {
+		candidates := mode.IconCandidates
+		if len(candidates) == 0 {
+			candidates = []CandidateScore{{Value: mode.IconName, Score: deliberateIconScore}}
+		}
+		for rank, candidate := range candidates {
+			claims = append(claims, claim{i, rank, candidate.Value, candidate.Score})
+		}
+	}
+	// Equal scores go to the earlier mode and its better-ranked candidate
+	sort.SliceStable(claims, func(i, j int) bool { return claims[i].score > claims[j].score })
+
+	owner := make(map[string]int)
+	assigned := make(map[int]bool)
+	for _, cl := range claims {
+		if _, taken := owner[cl.icon]; taken || assigned[cl.mode] {
+			continue
+		}
+		owner[cl.icon], assigned[cl.mode] = cl.mode, true
+		if cl.rank > 0 {
+			// The previous icon may not be claimed yet, e.g. when a runner-up outscores it
+			keptBy := ""
+			if i, ok := owner[modes[cl.mode].IconName]; ok {
+				keptBy = modes[i].Slug
+			}
+			reassignIcon(modes[cl.mode], cl.icon, keptBy)
+		}
+	}
+
+	shared := make(map[string][]string)
+	for _, mode := range modes {
+		shared[mode.IconName] = append(shared[mode.IconName], mode.Slug)
+	}
+	var duplicates []IconDuplicate
+	for _, icon := range sortedKeys(shared) {
+		if len(shared[icon]) > 1 {
+			duplicates = append(duplicates, IconDuplicate{Icon: icon, Modes: shared[icon]})
+		}
+	}
+	return duplicates
+}
+
+// reassignIcon switches a mode to a runner-up icon and records why in its trace.
+// keptBy names the mode that took the previous icon, if any.
+func reassignIcon(mode *KiloMode, icon, keptBy string) {
+	previous := mode.IconName
+	mode.IconName = icon
+	mode.Name = swapEmoji(mode.Name, previous, icon)
+	rule := fmt.Sprintf("next-best unique icon (instead of %s)", previous)
+	if keptBy != "" {
+		rule = fmt.Sprintf("next-best unique icon (%s went to %s)", previous, keptBy)
+	}
+	for i := range mode.Traces {
+		if trace := &mode.Traces[i]; trace.Field == "iconName" {
+			trace.Value = icon
+			trace.Rule = rule
+		}
+	}
+}
+
+// makeIconsUnique runs the -unique-icons pass over a set of modes and prints what it could not resolve
+func (c *Converter) makeIconsUnique(modes []*KiloMode) []IconDuplicate {
+	if !c.uniqueIcons || len(modes) < 2 {
+		return nil
+	}
+	duplicates := assignUniqueIcons(modes)
+	for _, duplicate := range duplicates {
+		fmt.Printf("  ⚠ %s is still shared by %s (no unused candidate icons left)\n", duplicate.Icon, strings.Join(duplicate.Modes, ", "))
+	}
+	return duplicates
+}
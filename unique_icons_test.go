@@ -0,0 +1,97 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestAssignUniqueIcons(t *testing.T) {
+	modes := []*KiloMode{
+		{Slug: "weak", IconName: "codicon-gear", IconCandidates: []CandidateScore{{Value: "codicon-gear", Score: 20}, {Value: "codicon-tools", Score: 5}},
+			Traces: []DecisionTrace{{Field: "iconName", Value: "codicon-gear", Rule: "highest keyword score (engineer)"}}},
+		{Slug: "strong", IconName: "codicon-gear", IconCandidates: []CandidateScore{{Value: "codicon-gear", Score: 30}, {Value: "codicon-rocket", Score: 10}}},
+		{Slug: "unmatched", IconName: "codicon-gear", IconCandidates: []CandidateScore{{Value: "codicon-gear"}}},
+		{Slug: "given", IconName: "codicon-rocket"}, // Set by the source format
+	}
+
+	duplicates := assignUniqueIcons(modes)
+
+	want := map[string]string{"weak": "codicon-tools", "strong": "codicon-gear", "unmatched": "codicon-gear", "given": "codicon-rocket"}
+	for _, mode := range modes {
+		if mode.IconName != want[mode.Slug] {
+			t.Errorf("%s: expected %s, got %s", mode.Slug, want[mode.Slug], mode.IconName)
+		}
+	}
+	if trace := modes[0].Traces[0]; trace.Value != "codicon-tools" || !strings.Contains(trace.Rule, "codicon-gear went to strong") {
+		t.Errorf("Expected the reassignment in the trace, got %+v", trace)
+	}
+	if len(duplicates) != 1 || duplicates[0].Icon != "codicon-gear" || strings.Join(duplicates[0].Modes, ",") != "strong,unmatched" {
+		t.Errorf("Expected codicon-gear shared by strong and unmatched, got %+v", duplicates)
+	}
+}
+
+func TestAssignUniqueIcons_UnclaimedPreviousIcon(t *testing.T) {
+	modes := []*KiloMode{
+		{Slug: "first", IconName: "codicon-gear", IconCandidates: []CandidateScore{{Value: "codicon-gear", Score: 30}}},
+		// A runner-up that outscores the mode's icon is assigned before anyone claims that icon
+		{Slug: "second", IconName: "codicon-book", IconCandidates: []CandidateScore{{Value: "codicon-book", Score: 5}, {Value: "codicon-beaker", Score: 10}},
+			Traces: []DecisionTrace{{Field: "iconName", Value: "codicon-book"}}},
+	}
+
+	assignUniqueIcons(modes)
+
+	if trace := modes[1].Traces[0]; trace.Value != "codicon-beaker" || trace.Rule != "next-best unique icon (instead of codicon-book)" {
+		t.Errorf("Expected no mode named for the unclaimed icon, got %+v", trace)
+	}
+}
+
+func TestConvertDirectory_UniqueIcons(t *testing.T) {
+	input := t.TempDir()
+	agents := map[string]string{
+		"python-pro.md":   "---\nname: python-pro\ndescription: Writes idiomatic Python code\n---\nYou write Python.\n",
+		"python-tutor.md": "---\nname: python-tutor\ndescription: Teaches Python and documents examples\n---\nYou teach Python.\n",
+		"helper.md":       "---\nname: helper\ndescription: Helps out\n---\nYou help.\n",
+		"assistant.md":    "---\nname: assistant\ndescription: Assists\n---\nYou assist.\n",
+	}
+	for name, content := range agents {
+		if err := os.WriteFile(filepath.Join(input, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	output := t.TempDir()
+	c := NewConverter()
+	c.uniqueIcons = true
+	if err := c.convertDirectory(input, output, false, false); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(output, "custom_modes.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file CustomModesFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		t.Fatal(err)
+	}
+	icons := make(map[string]string)
+	for _, mode := range file.CustomModes {
+		icons[mode.Slug] = mode.IconName
+	}
+	if icons["python-pro"] == icons["python-tutor"] {
+		t.Errorf("Expected the python modes to get different icons, both got %s", icons["python-pro"])
+	}
+
+	// Neither default-icon mode has a runner-up, so the report lists them
+	report, err := os.ReadFile(filepath.Join(input, "conversion-diagnostic-report.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(report), "## Duplicate Icons") || !strings.Contains(string(report), "| `codicon-gear` | assistant, helper |") {
+		t.Errorf("Expected the unavoidable duplicate in the report:\n%s", report)
+	}
+}
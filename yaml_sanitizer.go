@@ -14,39 +14,354 @@ type YAMLSanitizer struct {
 	longDescPattern    *regexp.Regexp
 	toolsStringPattern *regexp.Regexp
 	yamlKeyPattern     *regexp.Regexp
+	plainValuePattern  *regexp.Regexp
+
+	// Repairs, tried in order from least to most invasive
+	strategies []repairStrategy
+}
+
+// repairStrategy is one named fix for a class of malformed frontmatter
+type repairStrategy struct {
+	Name        string
+	Description string
+	Detect      func(lines []string) []int // 0-based indexes of the lines the fix would touch
+	Fix         func(lines []string) []string
+}
+
+// SanitizationRecord describes how the sanitizer rewrote one frontmatter block
+type SanitizationRecord struct {
+	Original   string
+	Sanitized  string
+	Keys       []string // Top-level keys whose values were rewritten
+	Strategies []string // Names of the repair strategies that fired, in order
+	StartLine  int      // Source line of the first frontmatter line, for diff hunks
+}
+
+// Diff renders the rewrite as a unified diff with line numbers from the source file
+func (r *SanitizationRecord) Diff(path string) string {
+	return unifiedDiff(r.Original, r.Sanitized, path+" (original)", path+" (sanitized)", max(r.StartLine-1, 0))
 }
 
 // NewYAMLSanitizer creates a new YAML sanitizer with predefined patterns
 func NewYAMLSanitizer() *YAMLSanitizer {
-	return &YAMLSanitizer{
+	ys := &YAMLSanitizer{
 		// Detect long descriptions that likely contain problematic content
 		longDescPattern: regexp.MustCompile(`^(\s*description:\s*)(.{200,}.*)$`),
 		// Detect tools field as comma-separated string instead of array
 		toolsStringPattern: regexp.MustCompile(`^(\s*tools:\s*)([^[\]]+(?:,\s*[^[\]]+)+)\s*$`),
 		// Pattern to match YAML key-value pairs
 		yamlKeyPattern: regexp.MustCompile(`^(\s*)(\w+):\s*(.*)$`),
+		// Top-level key with a plain (unquoted, non-block) value
+		plainValuePattern: regexp.MustCompile(`^([A-Za-z_][\w-]*):[ \t]+([^"'|>\[{&*!\s].*)$`),
+	}
+
+	ys.strategies = []repairStrategy{
+		{"tab-indentation", "Tabs in indentation → spaces", ys.detectTabIndentation, ys.fixTabIndentation},
+		{"smart-quotes", "Values wrapped in typographic quotes → double-quoted strings", ys.detectSmartQuotes, ys.fixSmartQuotes},
+		{"tools-string", "Tools field as comma-separated string → YAML array", ys.detectToolsString, ys.fixToolsString},
+		{"multiline-example", "Values with <example> blocks spanning lines → literal blocks", ys.detectMultilineExamples, ys.fixMultilineExamples},
+		{"unquoted-colon", "Plain values containing ': ' → double-quoted strings", ys.detectUnquotedColons, ys.fixUnquotedColons},
+		{"long-description", "Long descriptions with unescaped quotes → literal blocks", ys.detectLongDescription, ys.fixLongDescription},
+	}
+
+	return ys
+}
+
+// strategy returns the repair strategy with the given name, or nil
+func (ys *YAMLSanitizer) strategy(name string) *repairStrategy {
+	for i := range ys.strategies {
+		if ys.strategies[i].Name == name {
+			return &ys.strategies[i]
+		}
 	}
+	return nil
 }
 
 // SanitizeFrontmatter attempts to fix malformed YAML frontmatter
 func (ys *YAMLSanitizer) SanitizeFrontmatter(content string) (string, error) {
+	result, _, err := ys.Repair(content)
+	return result, err
+}
+
+// Repair applies repair strategies one at a time until the frontmatter parses,
+// returning the repaired YAML and the names of the strategies that fired
+func (ys *YAMLSanitizer) Repair(content string) (string, []string, error) {
 	lines := strings.Split(content, "\n")
-	var sanitizedLines []string
+	var applied []string
 
-	for _, line := range lines {
-		sanitizedLine := ys.sanitizeLine(line)
-		sanitizedLines = append(sanitizedLines, sanitizedLine)
+	err := validateFrontmatter(lines)
+	for _, strategy := range ys.strategies {
+		if err == nil {
+			break
+		}
+		if len(strategy.Detect(lines)) == 0 {
+			continue
+		}
+		lines = strategy.Fix(lines)
+		applied = append(applied, strategy.Name)
+		err = validateFrontmatter(lines)
 	}
 
-	result := strings.Join(sanitizedLines, "\n")
+	if err != nil {
+		return "", applied, fmt.Errorf("sanitization failed to produce valid YAML: %w", err)
+	}
+	return strings.Join(lines, "\n"), applied, nil
+}
 
-	// Validate the sanitized YAML
+// validateFrontmatter checks that the lines decode as an agent's frontmatter
+func validateFrontmatter(lines []string) error {
 	var testAgent ClaudeAgent
-	if err := yaml.Unmarshal([]byte(result), &testAgent); err != nil {
-		return "", fmt.Errorf("sanitization failed to produce valid YAML: %w", err)
+	return yaml.Unmarshal([]byte(strings.Join(lines, "\n")), &testAgent)
+}
+
+// detectTabIndentation finds lines indented with tabs, which YAML forbids
+func (ys *YAMLSanitizer) detectTabIndentation(lines []string) []int {
+	var found []int
+	for i, line := range lines {
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if strings.Contains(indent, "\t") && strings.TrimSpace(line) != "" {
+			found = append(found, i)
+		}
 	}
+	return found
+}
 
-	return result, nil
+// fixTabIndentation replaces each indenting tab with two spaces
+func (ys *YAMLSanitizer) fixTabIndentation(lines []string) []string {
+	fixed := append([]string(nil), lines...)
+	for _, i := range ys.detectTabIndentation(lines) {
+		content := strings.TrimLeft(lines[i], " \t")
+		indent := lines[i][:len(lines[i])-len(content)]
+		fixed[i] = strings.ReplaceAll(indent, "\t", "  ") + content
+	}
+	return fixed
+}
+
+// smartQuotePairs maps opening typographic quotes to their closing counterpart
+var smartQuotePairs = map[string]string{"“": "”", "‘": "’", "„": "“"}
+
+// smartQuotedValue returns the inner text when a line's value is wrapped in typographic quotes
+func (ys *YAMLSanitizer) smartQuotedValue(line string) (string, string, bool) {
+	match := ys.yamlKeyPattern.FindStringSubmatch(line)
+	if match == nil {
+		return "", "", false
+	}
+	value := strings.TrimSpace(match[3])
+	for open, closing := range smartQuotePairs {
+		if strings.HasPrefix(value, open) && strings.HasSuffix(value, closing) && len(value) > len(open)+len(closing) {
+			return line[:len(line)-len(match[3])], value[len(open) : len(value)-len(closing)], true
+		}
+	}
+	return "", "", false
+}
+
+// detectSmartQuotes finds values quoted with typographic quotes, which YAML reads as plain text
+func (ys *YAMLSanitizer) detectSmartQuotes(lines []string) []int {
+	var found []int
+	for i, line := range lines {
+		if _, _, ok := ys.smartQuotedValue(line); ok {
+			found = append(found, i)
+		}
+	}
+	return found
+}
+
+// fixSmartQuotes rewrites typographically quoted values as double-quoted strings
+func (ys *YAMLSanitizer) fixSmartQuotes(lines []string) []string {
+	fixed := append([]string(nil), lines...)
+	for i, line := range lines {
+		if prefix, inner, ok := ys.smartQuotedValue(line); ok {
+			fixed[i] = prefix + doubleQuote(inner)
+		}
+	}
+	return fixed
+}
+
+// detectToolsString finds tools given as a comma-separated string
+func (ys *YAMLSanitizer) detectToolsString(lines []string) []int {
+	var found []int
+	for i, line := range lines {
+		if ys.toolsStringPattern.MatchString(line) {
+			found = append(found, i)
+		}
+	}
+	return found
+}
+
+// fixToolsString converts comma-separated tools to a flow sequence
+func (ys *YAMLSanitizer) fixToolsString(lines []string) []string {
+	fixed := append([]string(nil), lines...)
+	for _, i := range ys.detectToolsString(lines) {
+		fixed[i] = ys.sanitizeLine(lines[i])
+	}
+	return fixed
+}
+
+// exampleSpan returns the index of the last line belonging to a value that opens an
+// <example> block on line i, or -1 when the value does not continue onto later lines
+func (ys *YAMLSanitizer) exampleSpan(lines []string, i int) int {
+	match := ys.plainValuePattern.FindStringSubmatch(lines[i])
+	if match == nil || !strings.Contains(match[2], "<example>") {
+		return -1
+	}
+
+	// The value runs through the last closing tag before the next top-level key
+	end := -1
+	for j := i + 1; j < len(lines); j++ {
+		if strings.Contains(lines[j], "</example>") || strings.Contains(lines[j], "</commentary>") {
+			end = j
+		}
+	}
+	if end < 0 {
+		return -1
+	}
+	for end+1 < len(lines) && strings.TrimSpace(lines[end+1]) != "" && !frontmatterKeyRe.MatchString(lines[end+1]) {
+		end++
+	}
+	return end
+}
+
+// detectMultilineExamples finds values whose <example> blocks continue on the following lines
+func (ys *YAMLSanitizer) detectMultilineExamples(lines []string) []int {
+	var found []int
+	for i := 0; i < len(lines); i++ {
+		if end := ys.exampleSpan(lines, i); end > i {
+			found = append(found, i)
+			i = end
+		}
+	}
+	return found
+}
+
+// fixMultilineExamples rewrites each spanning value as a literal block
+func (ys *YAMLSanitizer) fixMultilineExamples(lines []string) []string {
+	var fixed []string
+	for i := 0; i < len(lines); i++ {
+		end := ys.exampleSpan(lines, i)
+		if end <= i {
+			fixed = append(fixed, lines[i])
+			continue
+		}
+
+		match := ys.plainValuePattern.FindStringSubmatch(lines[i])
+		continuation := lines[i+1 : end+1]
+		indent := commonIndent(continuation)
+
+		fixed = append(fixed, match[1]+": |-", "  "+strings.TrimSpace(match[2]))
+		for _, line := range continuation {
+			if strings.TrimSpace(line) == "" {
+				fixed = append(fixed, "")
+			} else {
+				fixed = append(fixed, "  "+strings.TrimRight(line[indent:], " \t"))
+			}
+		}
+		i = end
+	}
+	return fixed
+}
+
+// commonIndent returns the smallest indentation among non-blank lines
+func commonIndent(lines []string) int {
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		width := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || width < indent {
+			indent = width
+		}
+	}
+	return max(indent, 0)
+}
+
+// plainValueEnd returns the index of the last line of a plain value starting on
+// line i, following indented continuation lines, or -1 if line i has no plain value
+func (ys *YAMLSanitizer) plainValueEnd(lines []string, i int) int {
+	if !ys.plainValuePattern.MatchString(lines[i]) {
+		return -1
+	}
+	end := i
+	for end+1 < len(lines) && strings.TrimSpace(lines[end+1]) != "" &&
+		strings.TrimLeft(lines[end+1], " \t") != lines[end+1] {
+		end++
+	}
+	return end
+}
+
+// detectUnquotedColons finds plain values containing ': ', which YAML reads as a nested mapping
+func (ys *YAMLSanitizer) detectUnquotedColons(lines []string) []int {
+	var found []int
+	for i := 0; i < len(lines); i++ {
+		end := ys.plainValueEnd(lines, i)
+		if end < 0 {
+			continue
+		}
+		value := ys.plainValuePattern.FindStringSubmatch(lines[i])[2]
+		for _, line := range lines[i+1 : end+1] {
+			value += " " + strings.TrimSpace(line)
+		}
+		if strings.Contains(value, ": ") || strings.HasSuffix(strings.TrimSpace(value), ":") {
+			found = append(found, i)
+		}
+		i = end
+	}
+	return found
+}
+
+// fixUnquotedColons double-quotes those values, folding continuation lines as YAML would
+func (ys *YAMLSanitizer) fixUnquotedColons(lines []string) []string {
+	flagged := make(map[int]bool)
+	for _, i := range ys.detectUnquotedColons(lines) {
+		flagged[i] = true
+	}
+
+	var fixed []string
+	for i := 0; i < len(lines); i++ {
+		if !flagged[i] {
+			fixed = append(fixed, lines[i])
+			continue
+		}
+		end := ys.plainValueEnd(lines, i)
+		match := ys.plainValuePattern.FindStringSubmatch(lines[i])
+		parts := []string{strings.TrimSpace(match[2])}
+		for _, line := range lines[i+1 : end+1] {
+			parts = append(parts, strings.TrimSpace(line))
+		}
+		fixed = append(fixed, match[1]+": "+doubleQuote(strings.Join(parts, " ")))
+		i = end
+	}
+	return fixed
+}
+
+// detectLongDescription finds long descriptions that likely contain problematic content
+func (ys *YAMLSanitizer) detectLongDescription(lines []string) []int {
+	var found []int
+	for i, line := range lines {
+		if ys.longDescPattern.MatchString(line) {
+			found = append(found, i)
+		}
+	}
+	return found
+}
+
+// fixLongDescription rewrites long descriptions as literal blocks or quoted strings
+func (ys *YAMLSanitizer) fixLongDescription(lines []string) []string {
+	var fixed []string
+	for _, line := range lines {
+		if ys.longDescPattern.MatchString(line) {
+			fixed = append(fixed, strings.Split(ys.sanitizeLine(line), "\n")...)
+		} else {
+			fixed = append(fixed, line)
+		}
+	}
+	return fixed
+}
+
+// doubleQuote renders text as a YAML double-quoted scalar
+func doubleQuote(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\\`)
+	return `"` + strings.ReplaceAll(text, `"`, `\"`) + `"`
 }
 
 // sanitizeLine handles sanitization of individual YAML lines
@@ -128,15 +443,47 @@ func (ys *YAMLSanitizer) DetectIssues(yamlContent string) []string {
 	var issues []string
 
 	lines := strings.Split(yamlContent, "\n")
-	for i, line := range lines {
-		if ys.longDescPattern.MatchString(line) {
-			issues = append(issues, fmt.Sprintf("Line %d: Long description with potential quote issues", i+1))
+	for _, strategy := range ys.strategies {
+		for _, i := range strategy.Detect(lines) {
+			issues = append(issues, fmt.Sprintf("Line %d: %s (%s)", i+1, strategy.Description, strategy.Name))
 		}
+	}
 
-		if ys.toolsStringPattern.MatchString(line) {
-			issues = append(issues, fmt.Sprintf("Line %d: Tools field as string instead of array", i+1))
+	return issues
+}
+
+// changedKeys lists the top-level keys whose text differs between two frontmatter blocks
+func changedKeys(original, sanitized string) []string {
+	before := topLevelBlocks(original)
+	after := topLevelBlocks(sanitized)
+
+	var keys []string
+	for _, key := range after.order {
+		if before.blocks[key] != after.blocks[key] {
+			keys = append(keys, key)
 		}
 	}
+	return keys
+}
 
-	return issues
+// frontmatterBlocks holds the raw text of each top-level key, in document order
+type frontmatterBlocks struct {
+	order  []string
+	blocks map[string]string
+}
+
+// topLevelBlocks splits frontmatter into each top-level key and its continuation lines
+func topLevelBlocks(content string) frontmatterBlocks {
+	result := frontmatterBlocks{blocks: make(map[string]string)}
+	current := ""
+	for _, line := range strings.Split(content, "\n") {
+		if match := frontmatterKeyRe.FindStringSubmatch(line); match != nil {
+			current = match[1]
+			result.order = append(result.order, current)
+		}
+		if current != "" {
+			result.blocks[current] += line + "\n"
+		}
+	}
+	return result
 }
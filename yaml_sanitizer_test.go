@@ -1,6 +1,9 @@
 package main
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -79,3 +82,60 @@ func TestDetectIssues(t *testing.T) {
 		t.Errorf("Expected 2 issues, got %d", len(issues))
 	}
 }
+
+// TestRepairStrategies_Corpus checks every strategy against its cases in testdata/repairs/<name>
+func TestRepairStrategies_Corpus(t *testing.T) {
+	s := NewYAMLSanitizer()
+	for _, strategy := range s.strategies {
+		inputs, _ := filepath.Glob(filepath.Join("testdata", "repairs", strategy.Name, "*.in.yaml"))
+		if len(inputs) == 0 {
+			t.Errorf("%s: no test corpus", strategy.Name)
+		}
+		for _, input := range inputs {
+			in, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := os.ReadFile(strings.TrimSuffix(input, ".in.yaml") + ".want.yaml")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			lines := strings.Split(string(in), "\n")
+			if validateFrontmatter(lines) == nil {
+				t.Errorf("%s: input is already valid YAML", input)
+			}
+			if len(strategy.Detect(lines)) == 0 {
+				t.Errorf("%s: not detected", input)
+				continue
+			}
+			got := strings.Join(strategy.Fix(lines), "\n")
+			if got != string(want) {
+				t.Errorf("%s: expected:\n%s\ngot:\n%s", input, want, got)
+			}
+			if err := validateFrontmatter(strings.Split(got, "\n")); err != nil {
+				t.Errorf("%s: fix is not valid YAML: %v", input, err)
+			}
+		}
+	}
+}
+
+func TestRepair_RecordsStrategies(t *testing.T) {
+	s := NewYAMLSanitizer()
+	input := "name: a\ndescription: “Use when: it rains”\ntools: Read, Grep"
+	got, applied, err := s.Repair(input)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if strings.Join(applied, ",") != "smart-quotes,tools-string" {
+		t.Errorf("Unexpected strategies: %v", applied)
+	}
+	if !strings.Contains(got, `description: "Use when: it rains"`) {
+		t.Errorf("Unexpected output:\n%s", got)
+	}
+
+	// Valid input needs no repairs
+	if _, applied, _ := s.Repair("name: a\ndescription: b"); len(applied) != 0 {
+		t.Errorf("Expected no strategies for valid YAML, got %v", applied)
+	}
+}